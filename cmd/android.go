@@ -0,0 +1,276 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/language"
+)
+
+// printf-style format specifiers: %s, %1$s, %.2f, %ld, %@ (iOS), %%
+const printfVerbPattern = `%(?:\d+\$)?[-+#0,(]*\d*(?:\.\d+)?(?:hh|h|ll|l|q|z|t|j|L)?[a-zA-Z@%]`
+
+// Markup, the entities and escape sequences left by unescapeAndroidText and
+// format arguments of Android string resources. <xliff:g> marks text that
+// must not be translated.
+var reAndroidPlaceholder = regexp.MustCompile(`(?s)<!\[CDATA\[|\]\]>|<xliff:g\b[^>]*>.*?</xliff:g>|<[^<>]+>|&[#\w]+;|\\u[0-9a-fA-F]{4}|\\\\|` + printfVerbPattern)
+
+// Line breaks in the source of unquoted resources, which Android reads as a
+// single space
+var reAndroidLineBreak = regexp.MustCompile(`[ \t]*\r?\n\s*`)
+
+// androidCmd represents the android command
+var androidCmd = &cobra.Command{
+	Use:   "android",
+	Short: "Translate Android strings.xml resources",
+	Long: `Translates <string>, <plurals> and <string-array> resources of an Android
+res/values/strings.xml file. Resources marked translatable="false", markup,
+<xliff:g> spans and format arguments such as %1$s are left untouched, and the
+comment preceding a resource is used as its context. Escapes such as \' and
+\n and entities such as &amp; are decoded before translation and written
+again afterwards.
+
+When --output is an existing directory (e.g. app/src/main/res) the result is
+written to the values-<qualifier> folder for the target language:
+
+  gootrago android -i res/values/strings.xml -o res -t uk   # res/values-uk/strings.xml`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isDir(outputFile) {
+			outputFile = filepath.Join(outputFile, "values-"+androidQualifier(targetLang), filepath.Base(inputFile))
+		}
+
+		return runFileHandler(translateAndroid)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(androidCmd)
+}
+
+// **************************************************************************
+// translateAndroid is the fileHandler of the android command. The raw
+// content of every translatable resource is translated with its markup
+// protected, re-escaped for the Android resource syntax and written back in
+// place, so comments and formatting of the file are kept as they are.
+// --------------------------------------------------------------------------
+func translateAndroid(name string, data []byte) ([]byte, error) {
+	data, enc, err := decodeXML(data)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := scanXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	root := xmlRootElement(toks)
+	if root < 0 {
+		return nil, fmt.Errorf("no <resources> element found")
+	}
+
+	var edits []xmlEdit
+	var segs []segment
+	add := func(start, end int, context string) {
+		edits = append(edits, xmlEdit{start: start, end: end})
+		segs = append(segs, segment{Text: string(data[start:end]), Context: context})
+	}
+
+	comment := ""
+	end := xmlElementEnd(toks, root)
+	for i := root + 1; i < end; i++ {
+		switch tok := toks[i].tok.(type) {
+		case xml.Comment:
+			comment = strings.TrimSpace(string(tok))
+		case xml.StartElement:
+			j := xmlElementEnd(toks, i)
+			resName, _ := xmlAttr(tok, "name")
+			context := strings.TrimSpace(resName + ": " + comment)
+			if translatable, _ := xmlAttr(tok, "translatable"); translatable != "false" {
+				switch tok.Name.Local {
+				case "string":
+					add(toks[i].end, toks[j].start, context)
+				case "plurals", "string-array":
+					for k := i + 1; k < j; k++ {
+						if item, ok := toks[k].tok.(xml.StartElement); ok && item.Name.Local == "item" {
+							m := xmlElementEnd(toks, k)
+							add(toks[k].end, toks[m].start, context)
+							k = m
+						}
+					}
+				}
+			}
+			comment = ""
+			i = j
+		}
+	}
+
+	// Strings are translated unescaped, without the whitespace around them
+	// and, when wrapped in double quotes, without the quotes
+	around := make([][2]string, len(segs))
+	quoted := make([]bool, len(segs))
+	for i, seg := range segs {
+		text := strings.TrimSpace(seg.Text)
+		open := strings.Index(seg.Text, text)
+		close := open + len(text)
+		if len(text) >= 2 && strings.HasPrefix(text, `"`) && strings.HasSuffix(text, `"`) && !strings.HasSuffix(text, `\"`) {
+			open, close, quoted[i] = open+1, close-1, true
+		}
+		around[i] = [2]string{seg.Text[:open], seg.Text[close:]}
+		segs[i].Text = unescapeAndroidText(seg.Text[open:close], quoted[i])
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(reAndroidPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, text := range strOut {
+		edits[i].text = around[i][0] + escapeAndroidText(text, quoted[i]) + around[i][1]
+	}
+
+	return encodeXML(applyXMLEdits(data, edits), enc)
+}
+
+// **************************************************************************
+// unescapeAndroidText turns the content of a string resource into the text
+// it stands for, so that escaped apostrophes and entities inside words reach
+// the translation as plain characters: \' \" \@ \? \n \t and entities are
+// decoded, and in unquoted strings line breaks of the source become spaces.
+// Markup (anything between < and >), \\, \uXXXX and the entities of < and >
+// are left alone.
+// --------------------------------------------------------------------------
+func unescapeAndroidText(s string, quoted bool) string {
+	if !quoted {
+		s = reAndroidLineBreak.ReplaceAllString(s, " ")
+	}
+
+	var sb strings.Builder
+	inTag := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '<':
+			inTag = true
+		case c == '>':
+			inTag = false
+		case inTag:
+		case c == '\\' && i+1 < len(s):
+			i++
+			switch s[i] {
+			case '\'', '"', '@', '?':
+				c = s[i]
+			case 'n':
+				c = '\n'
+			case 't':
+				c = '\t'
+			default:
+				sb.WriteByte(c)
+				c = s[i]
+			}
+		case c == '&':
+			entity := reEntityPrefix.FindString(s[i:])
+			if text := html.UnescapeString(entity); entity != "" && text != entity && text != "<" && text != ">" {
+				sb.WriteString(text)
+				i += len(entity) - 1
+				continue
+			}
+		}
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+// **************************************************************************
+// escapeAndroidText escapes a translated text for a string resource:
+// quotes, apostrophes outside double-quoted strings, line breaks, tabs, bare
+// ampersands and a leading @ or ?. Markup (anything between < and >) and
+// escape sequences are left alone.
+// --------------------------------------------------------------------------
+func escapeAndroidText(s string, quoted bool) string {
+	var sb strings.Builder
+	inTag := false
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '<':
+			inTag = true
+		case c == '>':
+			inTag = false
+		case inTag:
+		case c == '\\' && i+1 < len(s):
+			sb.WriteByte(c)
+			i++
+			c = s[i]
+		case c == '"' || c == '\'' && !quoted:
+			sb.WriteByte('\\')
+		case (c == '@' || c == '?') && i == 0 && !quoted:
+			sb.WriteByte('\\')
+		case c == '\n':
+			sb.WriteString(`\n`)
+			continue
+		case c == '\t':
+			sb.WriteString(`\t`)
+			continue
+		case c == '&' && !reEntityPrefix.MatchString(s[i:]):
+			sb.WriteString("&amp;")
+			continue
+		}
+		sb.WriteByte(c)
+	}
+
+	return sb.String()
+}
+
+var reEntityPrefix = regexp.MustCompile(`^&[#\w]+;`)
+
+// androidQualifier converts a BCP 47 language tag into the language
+// qualifier of an Android resource folder: "uk", "pt-rBR" or "b+sr+Latn"
+func androidQualifier(lang string) string {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang
+	}
+
+	base, _ := tag.Base()
+	script, scriptConf := tag.Script()
+	region, regionConf := tag.Region()
+	switch {
+	case scriptConf == language.Exact:
+		qualifier := "b+" + base.String() + "+" + script.String()
+		if regionConf == language.Exact {
+			qualifier += "+" + region.String()
+		}
+		return qualifier
+	case regionConf == language.Exact:
+		return base.String() + "-r" + region.String()
+	default:
+		return base.String()
+	}
+}
+
+// isDir reports whether path names an existing directory
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
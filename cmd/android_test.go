@@ -0,0 +1,94 @@
+package cmd
+
+import "testing"
+
+func TestUnescapeAndroidText(t *testing.T) {
+	tests := []struct {
+		in     string
+		quoted bool
+		want   string
+	}{
+		{`Don\'t save`, false, `Don't save`},
+		{`Say \"hi\"`, false, `Say "hi"`},
+		{`Tom &amp; Jerry&#39;s`, false, `Tom & Jerry's`},
+		{`a &lt;b&gt; &#60;`, false, `a &lt;b&gt; &#60;`},
+		{`One\nTwo\tThree`, false, "One\nTwo\tThree"},
+		{"Long text\n    continued", false, "Long text continued"},
+		{"Line one\nLine two", true, "Line one\nLine two"},
+		{`\@home \?attr`, false, `@home ?attr`},
+		{`C:\\dir \u00A9`, false, `C:\\dir \u00A9`},
+		{`<b title="it\'s">Don\'t</b>`, false, `<b title="it\'s">Don't</b>`},
+		{`Don't`, true, `Don't`},
+	}
+
+	for _, tt := range tests {
+		if got := unescapeAndroidText(tt.in, tt.quoted); got != tt.want {
+			t.Errorf("unescapeAndroidText(%q, %v) = %q, want %q", tt.in, tt.quoted, got, tt.want)
+		}
+	}
+}
+
+func TestEscapeAndroidText(t *testing.T) {
+	tests := []struct {
+		in     string
+		quoted bool
+		want   string
+	}{
+		{`Don't save`, false, `Don\'t save`},
+		{`Say "hi"`, false, `Say \"hi\"`},
+		{`Don't say "hi"`, true, `Don't say \"hi\"`},
+		{`Tom & Jerry &lt;3`, false, `Tom &amp; Jerry &lt;3`},
+		{"One\nTwo\tThree", false, `One\nTwo\tThree`},
+		{`@home ?attr`, false, `\@home ?attr`},
+		{`?attr`, true, `?attr`},
+		{`C:\\dir \u00A9 %1$s`, false, `C:\\dir \u00A9 %1$s`},
+		{`<a href="x'y">l'a</a>`, false, `<a href="x'y">l\'a</a>`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeAndroidText(tt.in, tt.quoted); got != tt.want {
+			t.Errorf("escapeAndroidText(%q, %v) = %q, want %q", tt.in, tt.quoted, got, tt.want)
+		}
+	}
+}
+
+func TestAndroidPlaceholders(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []chunk
+	}{
+		{`Don\'t save`, []chunk{{text: "Don't save"}}},
+		{`Tom &amp; Jerry`, []chunk{{text: "Tom & Jerry"}}},
+		{`Hello, %1$s!`, []chunk{{text: "Hello, "}, {text: "%1$s", keep: true}, {text: "!"}}},
+		{`Open <xliff:g id="app">Maps</xliff:g>`, []chunk{{text: "Open "}, {text: `<xliff:g id="app">Maps</xliff:g>`, keep: true}}},
+		{`<b>Don\'t</b>`, []chunk{{text: "<b>", keep: true}, {text: "Don't"}, {text: "</b>", keep: true}}},
+	}
+
+	protect := regexpProtector(reAndroidPlaceholder)
+	for _, tt := range tests {
+		got := protect(unescapeAndroidText(tt.in, false))
+		if len(got) != len(tt.want) {
+			t.Errorf("chunks of %q = %+v, want %+v", tt.in, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("chunks of %q = %+v, want %+v", tt.in, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTranslateAndroidKeepsLayout(t *testing.T) {
+	// Resources without letters are not sent for translation and must come
+	// back exactly as they were
+	data := "<resources>\n    <string name=\"a\">\n        %1$d / %2$d\n    </string>\n    <string name=\"b\">\"100 %%\"</string>\n    <string name=\"c\">\\@2 &amp; 3</string>\n</resources>\n"
+	got, err := translateAndroid("strings.xml", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("translateAndroid() = %q, want %q", got, data)
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Directories holding resource files inside archives
+var archiveLocaleDirs = []string{"locales", "locale", "i18n", "l10n", "lang", "langs", "translations"}
+
+// Extensions of resource formats that are just as often used for
+// configuration and data, and are only translated inside archives when the
+// entry is named or placed like a locale file
+var archiveLocaleExts = []string{".json", ".yaml", ".yml", ".toml", ".properties"}
+
+// Handlers of the files translated inside archives, by lower-case base name
+// or extension; base names take precedence
+var archiveHandlers = map[string]fileHandler{
+	// Resource files
+	".json": translateI18n, ".arb": translateI18n,
+	".yaml": translateYAML, ".yml": translateYAML,
+	".strings": translateIOS, ".stringsdict": translateIOS,
+	".properties": translateProperties, ".toml": translateTOML,
+	"strings.xml": translateAndroid,
+
+	// Subtitles
+	".srt": translateSubtitles, ".vtt": translateSubtitles, ".ass": translateSubtitles, ".ssa": translateSubtitles,
+
+	// Documents
+	".docx": translateOffice, ".docm": translateOffice, ".dotx": translateOffice,
+	".pptx": translateOffice, ".pptm": translateOffice, ".potx": translateOffice,
+	".xlsx": translateOffice, ".xlsm": translateOffice, ".xltx": translateOffice,
+	".odt": translateODF, ".ott": translateODF, ".ods": translateODF,
+	".ots": translateODF, ".odp": translateODF, ".otp": translateODF,
+	".epub": translateEPUB, ".ipynb": translateNotebook,
+	".adoc": proseHandler(scanAsciiDoc, reAsciiDocPlaceholder),
+	".rst":  proseHandler(scanRST, reRSTPlaceholder),
+	".tex":  proseHandler(scanLaTeX, reLaTeXPlaceholder),
+}
+
+// archiveCmd represents the archive command
+var archiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Translate the supported files inside ZIP and tar archives",
+	Long: `Translates every supported file inside a ZIP or tar archive (plain, or
+compressed with gzip, or with bzip2 when named .tar.bz2 or .tbz2) and writes a
+new archive with the same layout.
+Files are chosen by name:
+
+  .json, .arb                 i18n resources (see the i18n command)
+  .yaml, .yml                 YAML resources
+  strings.xml                 Android string resources
+  .strings, .stringsdict      iOS string resources
+  .properties, .toml          Java resource bundles, TOML catalogs
+  .srt, .vtt, .ass, .ssa      subtitles
+  .docx, .pptx, .xlsx, ...    Office documents
+  .odt, .ods, .odp, ...       OpenDocument files
+  .epub, .adoc, .rst, .tex    e-books and markup documents
+  .ipynb                      Jupyter notebooks
+
+.json, .yaml, .yml, .toml and .properties files are only translated when
+named after a language (en.json, active.uk.toml, messages_de.properties) or
+inside a locale directory (locales/, i18n/, en/, ...), so that configuration
+such as package.json, ci.yml or application.properties is left alone. All
+other entries are copied unchanged. An output name ending with .gz or
+.tgz gives a gzip-compressed archive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateArchive)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(archiveCmd)
+}
+
+// **************************************************************************
+// translateArchive is the fileHandler of the archive command. ZIP archives
+// are recognized by their signature; anything else is read as tar.
+// --------------------------------------------------------------------------
+func translateArchive(name string, data []byte) ([]byte, error) {
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) || bytes.HasPrefix(data, []byte("PK\x05\x06")) {
+		return translateZipArchive(data)
+	}
+
+	return translateTarArchive(data)
+}
+
+// archiveHandler returns the handler of an archive entry, or nil. Metadata
+// left by macOS (__MACOSX, ._ files) is never translated, nor are JSON,
+// YAML, TOML and properties files that are not locale files, such as
+// package.json, ci.yml, Cargo.toml or application.properties.
+func archiveHandler(name string) fileHandler {
+	base := strings.ToLower(path.Base(name))
+	if strings.HasPrefix(base, "._") || strings.HasPrefix(name, "__MACOSX/") {
+		return nil
+	}
+	if handler, ok := archiveHandlers[base]; ok {
+		return handler
+	}
+	if slices.Contains(archiveLocaleExts, path.Ext(base)) && !isLocaleFileName(name) {
+		return nil
+	}
+
+	return archiveHandlers[path.Ext(base)]
+}
+
+// isLocaleFileName tells whether an archive entry is a locale file: a
+// go-i18n file (active.uk.json), a file named after a language (en.json,
+// pt-BR.yml) or with a language suffix (messages_de.properties), or a file
+// inside a locale directory (locales/, i18n/, or one named after a language
+// as in public/locales/en/common.json)
+func isLocaleFileName(name string) bool {
+	stem := strings.TrimSuffix(path.Base(name), path.Ext(name))
+	if detectI18nFormat(name) == i18nGoI18n || isLanguageCode(stem) {
+		return true
+	}
+	if m := reBundleLocale.FindStringSubmatch(stem); m != nil && isLanguageCode(m[1]) {
+		return true
+	}
+
+	dirs := strings.Split(path.Dir(name), "/")
+	for _, dir := range dirs {
+		if slices.Contains(archiveLocaleDirs, strings.ToLower(dir)) || isLanguageCode(dir) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func translateZipArchive(data []byte) ([]byte, error) {
+	entries, err := readZipEntries(data, func(name string) bool {
+		return !strings.HasSuffix(name, "/") && archiveHandler(name) != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		translated, err := archiveHandler(name)(name, entries[name])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		entries[name] = translated
+	}
+
+	return writeZip(data, entries)
+}
+
+// translateTarArchive copies a tar archive, translating the regular files
+// with a handler; headers are kept apart from the size
+func translateTarArchive(data []byte) ([]byte, error) {
+	tr := tar.NewReader(bytes.NewReader(data))
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid archive: %v", err)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %v", hdr.Name, err)
+		}
+
+		if handler := archiveHandler(hdr.Name); handler != nil && hdr.Typeflag == tar.TypeReg {
+			if content, err = handler(hdr.Name, content); err != nil {
+				return nil, fmt.Errorf("%v: %v", hdr.Name, err)
+			}
+			hdr.Size = int64(len(content))
+		}
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("failed to write %v: %v", hdr.Name, err)
+		}
+		if _, err := tw.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write %v: %v", hdr.Name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import "testing"
+
+func TestArchiveHandlerLocaleFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"package.json", false},
+		{"config/settings.json", false},
+		{"data/cities.json", false},
+		{"node_modules/lib/package.json", false},
+		{"en.json", true},
+		{"src/i18n/pt-BR.json", true},
+		{"lang/messages.json", true},
+		{"public/locales/en/common.json", true},
+		{"assets/translations/app.json", true},
+		{"active.uk.json", true},
+		{"l10n/app_en.arb", true},
+		{"app_en.arb", true},
+		{"__MACOSX/locales/._en.json", false},
+		{"go.mod", false},
+		{"Cargo.toml", false},
+		{".github/workflows/ci.yml", false},
+		{"docker-compose.yaml", false},
+		{"src/main/resources/application.properties", false},
+		{"config/locales/en.yml", true},
+		{"messages_de.properties", true},
+		{"i18n/messages.properties", true},
+		{"messages_pt_BR.properties", true},
+		{"labels_new.properties", false},
+		{"active.fr.toml", true},
+		{"locales/de.toml", true},
+		{"res/values/strings.xml", true},
+		{"subs/film.srt", true},
+	}
+
+	for _, tt := range tests {
+		if got := archiveHandler(tt.name) != nil; got != tt.want {
+			t.Errorf("archiveHandler(%q) != nil is %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Inline markup of AsciiDoc: monospace and passthrough text, attribute
+	// references, cross references, the target part of macros (link:, image:,
+	// kbd:, footnote:) and of URLs, description list and table separators
+	reAsciiDocPlaceholder = regexp.MustCompile("`[^`]*`|\\+\\+\\+.*?\\+\\+\\+|\\+[^+\\s][^+]*\\+|pass:[a-z,]*\\[[^\\]]*\\]|\\{[\\w-]+\\}|<<[^,>]*,?|>>|\\b[a-z]+:{1,2}[^\\s\\[]*\\[|https?://[^\\s\\[]+\\[?|\\]|:{2,4}(?:\\s|$)|;;|\\|")
+
+	// Opening lines of blocks whose content is not prose: listing, literal,
+	// passthrough and comment blocks, and fenced code
+	reAsciiDocVerbatim = regexp.MustCompile("^(?:-{4,}|\\.{4,}|\\+{4,}|/{4,}|```)")
+	// Lines kept as they are: comments, attribute entries, block attributes
+	// and anchors, delimiters of prose blocks and tables, list continuations
+	// and block macros
+	reAsciiDocKeep = regexp.MustCompile(`^(?://|:[\w-]+!?:|\[.*\]$|={4,}$|\*{4,}$|_{4,}$|--$|\|={3,}$|\+$|[a-z]+::\S*\[.*\]$)`)
+	// Section titles, Markdown style headings included
+	reAsciiDocHeading = regexp.MustCompile(`^((?:=+|#+)\s+)(.*)$`)
+	// Items of ordered, unordered and check lists, and admonition paragraphs
+	reAsciiDocItem = regexp.MustCompile(`^(\s*(?:(?:[*\-]+|\.+|\d+\.|[a-z]\.)(?:\s+\[[ x*]\])?|NOTE:|TIP:|IMPORTANT:|WARNING:|CAUTION:)\s+)(.*)$`)
+	// Block titles like .Example
+	reAsciiDocTitle = regexp.MustCompile(`^(\.)([^.\s].*)$`)
+)
+
+// asciidocCmd represents the asciidoc command
+var asciidocCmd = &cobra.Command{
+	Use:   "asciidoc",
+	Short: "Translate AsciiDoc documents",
+	Long: `Translates the prose of AsciiDoc documents (.adoc): paragraphs, section and
+block titles, list items, admonitions and table cells. Listing, literal,
+passthrough and comment blocks, attribute entries, block attributes and block
+macros are copied unchanged, and so are monospace text, attribute references,
+cross references and the targets of macros and links; the text of links and
+footnotes is translated.
+
+Paragraphs spanning several lines are re-wrapped to the width of their longest
+line.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(proseHandler(scanAsciiDoc, reAsciiDocPlaceholder))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(asciidocCmd)
+	addEncodingFlags(asciidocCmd)
+}
+
+// **************************************************************************
+// scanAsciiDoc returns the paragraphs of an AsciiDoc document. Titles, list
+// items and table rows take one line each; other paragraphs run to the next
+// blank line or block boundary. Indented lines outside of lists are literal
+// paragraphs and kept.
+// --------------------------------------------------------------------------
+func scanAsciiDoc(lines []string) []proseParagraph {
+	var paras []proseParagraph
+	var cur *proseParagraph
+	end := func() {
+		if cur != nil {
+			paras = append(paras, *cur)
+			cur = nil
+		}
+	}
+	single := func(i int, prefix, text string) {
+		end()
+		p := proseParagraph{prefix: prefix}
+		p.add(i, text)
+		paras = append(paras, p)
+	}
+
+	delim := ""      // Closing delimiter of the verbatim block being skipped
+	literal := false // Whether a literal paragraph is being skipped
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case delim != "":
+			if trimmed == delim {
+				delim = ""
+			}
+			continue
+		case literal:
+			literal = trimmed != ""
+			continue
+		}
+
+		var m []string
+		switch {
+		case trimmed == "":
+			end()
+		case reAsciiDocVerbatim.MatchString(line):
+			end()
+			delim = trimmed
+			if strings.HasPrefix(trimmed, "```") {
+				delim = "```"
+			}
+		case reAsciiDocKeep.MatchString(line):
+			end()
+		case strings.HasPrefix(line, "|"):
+			single(i, "", line)
+		case cur == nil && (line[0] == ' ' || line[0] == '\t'):
+			literal = true
+		default:
+			if m = reAsciiDocHeading.FindStringSubmatch(line); m != nil {
+				single(i, m[1], m[2])
+			} else if m = reAsciiDocTitle.FindStringSubmatch(line); m != nil && cur == nil {
+				single(i, m[1], m[2])
+			} else if m = reAsciiDocItem.FindStringSubmatch(line); m != nil {
+				end()
+				cur = &proseParagraph{prefix: m[1]}
+				cur.add(i, m[2])
+			} else {
+				if cur == nil {
+					cur = &proseParagraph{}
+				}
+				cur.add(i, line)
+			}
+		}
+	}
+	end()
+
+	return paras
+}
@@ -0,0 +1,243 @@
+/*
+This file implements the conversion of input and output files between UTF-8,
+used internally, and legacy character encodings such as Windows-1251 or
+ISO-8859-1. Input is converted when --input-encoding is set; with "auto" the
+encoding is detected from a byte order mark, or guessed from the content when
+it is not valid UTF-8. Output is written in the encoding of the input, or in
+the one given with --output-encoding, and characters that the output
+encoding cannot represent are an error rather than being replaced.
+
+Only the commands reading plain text streams have these flags, and properties
+only --input-encoding, as --properties-encoding chooses its output. The xml and
+android commands take the encoding from the XML declaration and write the
+output back in it, and ios reads and writes UTF-16 .strings files by their
+byte order mark. All other commands read and write UTF-8, which JSON, YAML,
+TOML, Go sources and the XML inside Office, OpenDocument and EPUB files use in
+practice.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/ianaindex"
+	"golang.org/x/text/encoding/unicode"
+	"golang.org/x/text/transform"
+)
+
+// Number of bytes looked at when detecting the encoding of an input
+const charsetSniffSize = 64 * 1024
+
+// Encoding of the last input converted by decodeInput and its name, or nil
+// for UTF-8; the output is written in it unless --output-encoding is set
+var (
+	inputCharset     encoding.Encoding
+	inputCharsetName string
+)
+
+// addEncodingFlags adds --input-encoding and --output-encoding to a command
+// reading and writing plain text streams
+func addEncodingFlags(cmd *cobra.Command) {
+	addInputEncodingFlag(cmd)
+	cmd.Flags().StringVarP(&outputEncoding, "output-encoding", "", "", "Character encoding of the output (e.g., 'windows-1251', 'utf-8'); the encoding of the input by default")
+}
+
+// addInputEncodingFlag adds --input-encoding alone, to a command with its own
+// choice of output encoding
+func addInputEncodingFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&inputEncoding, "input-encoding", "", "", "Character encoding of the input (e.g., 'windows-1251', 'iso-8859-1') or 'auto' to detect it; UTF-8 by default")
+}
+
+// lookupEncoding returns the encoding of a name such as windows-1251,
+// ISO-8859-1, cp1251 or UTF-16LE, or nil for UTF-8
+func lookupEncoding(name string) (encoding.Encoding, error) {
+	if isUTF8Name(name) {
+		return nil, nil
+	}
+
+	enc, err := ianaindex.IANA.Encoding(name)
+	if err != nil || enc == nil {
+		enc, err = htmlindex.Get(name)
+	}
+	if err != nil || enc == nil {
+		return nil, fmt.Errorf("unsupported character encoding: %v", name)
+	}
+
+	return enc, nil
+}
+
+// isUTF8Name tells whether an encoding name stands for UTF-8, which is also
+// the meaning of an empty name
+func isUTF8Name(name string) bool {
+	name = strings.ToLower(name)
+
+	return name == "" || name == "utf-8" || name == "utf8"
+}
+
+// **************************************************************************
+// decodeInput returns a reader converting r from the input encoding to
+// UTF-8, and records the encoding in inputCharset. With "auto" the encoding
+// is detected by detectEncoding; data with NUL bytes and no byte order mark
+// is taken for binary and left alone.
+// --------------------------------------------------------------------------
+func decodeInput(r io.Reader) (io.Reader, error) {
+	inputCharset, inputCharsetName = nil, ""
+	if isUTF8Name(inputEncoding) {
+		return r, nil
+	}
+
+	br := bufio.NewReaderSize(r, charsetSniffSize)
+	name := inputEncoding
+	var enc encoding.Encoding
+	if strings.EqualFold(name, "auto") {
+		head, _ := br.Peek(charsetSniffSize)
+		name = detectEncoding(head)
+		switch name {
+		case "utf-8":
+			return br, nil
+		case "utf-16le":
+			// The byte order mark is removed, and written again
+			enc = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+		case "utf-16be":
+			enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+		}
+	}
+
+	if enc == nil {
+		var err error
+		if enc, err = lookupEncoding(name); err != nil {
+			return nil, err
+		}
+	}
+	inputCharset, inputCharsetName = enc, name
+
+	return transform.NewReader(br, enc.NewDecoder()), nil
+}
+
+// **************************************************************************
+// detectEncoding guesses the encoding of data starting with head:
+//  1. utf-16le or utf-16be for a UTF-16 byte order mark, UTF-8 for a UTF-8
+//     one
+//  2. UTF-8 for binary data (with NUL bytes), which is not converted
+//  3. UTF-8 for valid UTF-8
+//  4. windows-1251 when most letters outside ASCII are Cyrillic in that
+//     encoding, windows-1252 (a superset of ISO-8859-1) otherwise
+//
+// --------------------------------------------------------------------------
+func detectEncoding(head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte{0xff, 0xfe}):
+		return "utf-16le"
+	case bytes.HasPrefix(head, []byte{0xfe, 0xff}):
+		return "utf-16be"
+	case bytes.HasPrefix(head, utf8BOM), bytes.IndexByte(head, 0) >= 0:
+		return "utf-8"
+	}
+
+	// A full sample may end in the middle of a character
+	valid := head
+	for i := 0; len(head) == charsetSniffSize && i < utf8.UTFMax-1; i++ {
+		if r, size := utf8.DecodeLastRune(valid); r != utf8.RuneError || size > 1 {
+			break
+		}
+		valid = valid[:len(valid)-1]
+	}
+	if utf8.Valid(valid) {
+		return "utf-8"
+	}
+
+	// Windows-1251 puts the Cyrillic alphabet at 0xC0-0xFF, where Latin
+	// encodings have accented letters that are rare next to ASCII ones
+	ascii, high := 0, 0
+	for _, b := range head {
+		switch {
+		case b >= 'A' && b <= 'Z', b >= 'a' && b <= 'z':
+			ascii++
+		case b >= 0xc0:
+			high++
+		}
+	}
+	if high > ascii/2 {
+		return "windows-1251"
+	}
+
+	return "windows-1252"
+}
+
+// encodedFile converts what is written to it from UTF-8 to another encoding
+type encodedFile struct {
+	w    io.WriteCloser // Converting writer
+	out  io.Closer      // Writer below it
+	enc  encoding.Encoding
+	name string
+}
+
+// **************************************************************************
+// encodeOutput returns a writer converting UTF-8 to the output encoding, or
+// to the encoding of the input when it is not set, and writing the result to
+// w; closing it closes w. Characters that cannot be represented are
+// reported by Write with the first of them.
+// --------------------------------------------------------------------------
+func encodeOutput(w io.WriteCloser) (io.WriteCloser, error) {
+	enc, name := inputCharset, inputCharsetName
+	if outputEncoding != "" {
+		var err error
+		if enc, err = lookupEncoding(outputEncoding); err != nil {
+			return nil, err
+		}
+		name = outputEncoding
+	}
+	if enc == nil {
+		return w, nil
+	}
+
+	return &encodedFile{w: transform.NewWriter(w, enc.NewEncoder()), out: w, enc: enc, name: name}, nil
+}
+
+func (f *encodedFile) Write(p []byte) (int, error) {
+	n, err := f.w.Write(p)
+	if err != nil {
+		return n, f.explain(p, err)
+	}
+
+	return n, nil
+}
+
+// Close writes what remains to be converted and closes the writer below
+func (f *encodedFile) Close() error {
+	err := f.w.Close()
+	if cerr := f.out.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+// explain turns an error of the encoder into one naming the first
+// character of p that the encoding cannot represent
+func (f *encodedFile) explain(p []byte, err error) error {
+	for _, r := range string(p) {
+		if _, err := f.enc.NewEncoder().String(string(r)); err != nil {
+			return fmt.Errorf("character %q (U+%04X) cannot be represented in %v", r, r, f.name)
+		}
+	}
+
+	return fmt.Errorf("text cannot be represented in %v: %v", f.name, err)
+}
+
+// outputIsUTF8 tells whether the output is written in UTF-8
+func outputIsUTF8() bool {
+	if outputEncoding == "" {
+		return inputCharset == nil
+	}
+
+	return isUTF8Name(outputEncoding)
+}
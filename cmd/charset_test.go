@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// nopWriteCloser lets a buffer stand for an output file
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestDetectEncoding(t *testing.T) {
+	cp1251, _ := charmap.Windows1251.NewEncoder().String("Привіт, світе! Як справи?")
+	cp1252, _ := charmap.Windows1252.NewEncoder().String("Café crème, à bientôt")
+	full := strings.Repeat("a", charsetSniffSize-1) + "é" // Cut in the middle of é
+
+	tests := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"empty", "", "utf-8"},
+		{"ascii", "hello", "utf-8"},
+		{"utf-8", "Привіт", "utf-8"},
+		{"utf-8 bom", "\ufeffhello", "utf-8"},
+		{"utf-16le bom", "\xff\xfeh\x00i\x00", "utf-16le"},
+		{"utf-16be bom", "\xfe\xff\x00h\x00i", "utf-16be"},
+		{"binary", "PK\x03\x04\x00\x00\xc3", "utf-8"},
+		{"windows-1251", cp1251, "windows-1251"},
+		{"windows-1252", cp1252, "windows-1252"},
+		{"cut sample", full[:charsetSniffSize], "utf-8"},
+	}
+
+	for _, tt := range tests {
+		if got := detectEncoding([]byte(tt.head)); got != tt.want {
+			t.Errorf("%v: detectEncoding() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCharsetRoundTrip(t *testing.T) {
+	defer func(in, out string) { inputEncoding, outputEncoding = in, out }(inputEncoding, outputEncoding)
+
+	cp1251, _ := charmap.Windows1251.NewEncoder().String("Привіт, світе!")
+	tests := []struct {
+		name   string
+		in     string
+		inEnc  string
+		outEnc string
+		text   string // Decoded input
+		out    string // Output written from the decoded input
+	}{
+		{"utf-8", "Привіт", "", "", "Привіт", "Привіт"},
+		{"auto utf-8", "Привіт", "auto", "", "Привіт", "Привіт"},
+		{"windows-1251 kept", cp1251, "windows-1251", "", "Привіт, світе!", cp1251},
+		{"auto windows-1251 kept", cp1251, "auto", "", "Привіт, світе!", cp1251},
+		{"windows-1251 to utf-8", cp1251, "cp1251", "utf-8", "Привіт, світе!", "Привіт, світе!"},
+		{"utf-8 to windows-1251", "Привіт, світе!", "", "windows-1251", "Привіт, світе!", cp1251},
+		{"utf-16le kept", "\xff\xfeh\x00\xe9\x00", "auto", "", "hé", "\xff\xfeh\x00\xe9\x00"},
+		{"utf-16be kept", "\xfe\xff\x00h\x00\xe9", "auto", "", "hé", "\xfe\xff\x00h\x00\xe9"},
+		{"binary untouched", "PK\x03\x04\x00\xff", "auto", "", "PK\x03\x04\x00\xff", "PK\x03\x04\x00\xff"},
+	}
+
+	for _, tt := range tests {
+		inputEncoding, outputEncoding = tt.inEnc, tt.outEnc
+		r, err := decodeInput(strings.NewReader(tt.in))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		text, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if string(text) != tt.text {
+			t.Errorf("%v: decoded %q, want %q", tt.name, text, tt.text)
+		}
+
+		var buf bytes.Buffer
+		w, err := encodeOutput(nopWriteCloser{&buf})
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if _, err := w.Write(text); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if buf.String() != tt.out {
+			t.Errorf("%v: wrote %q, want %q", tt.name, buf.String(), tt.out)
+		}
+	}
+}
+
+func TestEncodeOutputUnrepresentable(t *testing.T) {
+	defer func(in, out string) { inputEncoding, outputEncoding = in, out }(inputEncoding, outputEncoding)
+	inputEncoding, outputEncoding = "", "windows-1251"
+	inputCharset, inputCharsetName = nil, ""
+
+	var buf bytes.Buffer
+	w, err := encodeOutput(nopWriteCloser{&buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = io.WriteString(w, "Привіт ✓")
+	if err == nil {
+		err = w.Close()
+	}
+	if err == nil || !strings.Contains(err.Error(), "U+2713") {
+		t.Errorf("error = %v, want one naming U+2713", err)
+	}
+
+	outputEncoding = "no-such-encoding"
+	if _, err := encodeOutput(nopWriteCloser{&buf}); err == nil {
+		t.Errorf("encodeOutput() accepted an unknown encoding")
+	}
+}
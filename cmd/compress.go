@@ -0,0 +1,142 @@
+/*
+This file implements transparent compression of input and output files.
+Inputs compressed with gzip are recognized by their magic bytes and
+decompressed while reading, whatever their name; bzip2, whose magic bytes
+"BZh" may well start a text, is only decompressed for names ending with .bz2,
+.tbz2 or .tbz. Outputs whose name ends with .gz or .tgz are compressed with
+gzip.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Magic bytes of compressed streams
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	bzip2Magic = []byte("BZh")
+)
+
+// Extensions of compressed files and the extensions they stand for once
+// decompressed
+var compressedExts = map[string]string{".gz": "", ".bz2": "", ".tgz": ".tar", ".tbz2": ".tar", ".tbz": ".tar"}
+
+// compressedFile closes a decompressing or compressing stream together with
+// the file below it
+type compressedFile struct {
+	io.Reader
+	io.Writer
+	closers []io.Closer
+}
+
+// Close closes the stream and then the file, returning the first error
+func (f *compressedFile) Close() error {
+	var first error
+	for _, c := range f.closers {
+		if err := c.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+
+	return first
+}
+
+// **************************************************************************
+// openInput opens a file for reading, decompressing gzip and bzip2 content
+// and converting it from the input encoding (see decodeInput).
+// --------------------------------------------------------------------------
+func openInput(path string) (io.ReadCloser, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := decompressReader(bufio.NewReader(fh), path)
+	if err != nil {
+		fh.Close()
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	closers := []io.Closer{fh}
+	if closer, ok := r.(io.Closer); ok {
+		closers = []io.Closer{closer, fh}
+	}
+
+	// The content is converted to UTF-8 once decompressed
+	if r, err = decodeInput(r); err != nil {
+		fh.Close()
+		return nil, err
+	}
+
+	return &compressedFile{Reader: r, closers: closers}, nil
+}
+
+// decompressReader returns a reader decompressing r if it starts with the
+// magic bytes of gzip, or of bzip2 in a file named like a bzip2 one, or r
+// itself
+func decompressReader(r *bufio.Reader, path string) (io.Reader, error) {
+	magic, _ := r.Peek(3)
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip data: %v", err)
+		}
+		return zr, nil
+	case bytes.HasPrefix(magic, bzip2Magic) && isBzip2Name(path):
+		return bzip2.NewReader(r), nil
+	}
+
+	return r, nil
+}
+
+// **************************************************************************
+// createOutput creates (or truncates) a file for writing, compressing the
+// content with gzip when the name ends with .gz or .tgz and converting it to
+// the output encoding (see encodeOutput). The returned writer must be
+// closed, and its error checked, for the compressed stream to be complete.
+// --------------------------------------------------------------------------
+func createOutput(path string) (io.WriteCloser, error) {
+	fh, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if ext := strings.ToLower(filepath.Ext(path)); ext != ".gz" && ext != ".tgz" {
+		return encodeOutput(fh)
+	}
+
+	zw := gzip.NewWriter(fh)
+	zw.Name = uncompressedName(filepath.Base(path))
+
+	// The content is converted from UTF-8 before being compressed
+	return encodeOutput(&compressedFile{Writer: zw, closers: []io.Closer{zw, fh}})
+}
+
+// isBzip2Name tells whether a file name has the extension of a bzip2 file
+func isBzip2Name(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".bz2", ".tbz2", ".tbz":
+		return true
+	}
+
+	return false
+}
+
+// uncompressedName removes the extension of a compressed file from a name,
+// so that data.csv.gz gives data.csv and bundle.tgz gives bundle.tar
+func uncompressedName(name string) string {
+	ext := filepath.Ext(name)
+	if replacement, ok := compressedExts[strings.ToLower(ext)]; ok {
+		return strings.TrimSuffix(name, ext) + replacement
+	}
+
+	return name
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecompressReader(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write([]byte("hello"))
+	zw.Close()
+
+	tests := []struct {
+		path string
+		data []byte
+		want string
+	}{
+		{"notes.txt", []byte("BZh is how the file starts"), "BZh is how the file starts"},
+		{"notes.csv", []byte("BZh,1\n"), "BZh,1\n"},
+		{"plain.bz2", []byte("plain"), "plain"},
+		{"data.csv", gz.Bytes(), "hello"},
+		{"data.csv.gz", gz.Bytes(), "hello"},
+	}
+
+	for _, tt := range tests {
+		r, err := decompressReader(bufio.NewReader(bytes.NewReader(tt.data)), tt.path)
+		if err != nil {
+			t.Errorf("decompressReader(%q) error: %v", tt.path, err)
+			continue
+		}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Errorf("reading %q: %v", tt.path, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("decompressReader(%q) read %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestUncompressedName(t *testing.T) {
+	for name, want := range map[string]string{
+		"data.csv.gz": "data.csv", "bundle.tgz": "bundle.tar", "bundle.tbz": "bundle.tar",
+		"notes.bz2": "notes", "plain.csv": "plain.csv", "UP.CSV.GZ": "UP.CSV",
+	} {
+		if got := uncompressedName(name); got != want {
+			t.Errorf("uncompressedName(%q) = %q, want %q", name, got, want)
+		}
+	}
+	if !strings.HasSuffix(uncompressedName("x.tar.bz2"), ".tar") {
+		t.Errorf("uncompressedName(x.tar.bz2) = %q", uncompressedName("x.tar.bz2"))
+	}
+}
@@ -17,18 +17,84 @@ package cmd
 
 import (
 	"fmt"
+	"html"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/spf13/cobra"
 )
 
+// Modes of --new-columns
+const (
+	csvAppend = "append"
+	csvInsert = "insert"
+)
+
 // csvCmd represents the csv command
 var csvCmd = &cobra.Command{
 	Use:   "csv",
 	Short: "Translate CSV files or specific columns",
 	Long: `A flexible CSV translation tool that can translate entire files or specific columns while preserving the original structure. 
-Supports both Basic and Advanced Google Cloud Translation APIs and various CSV formats.`,
+Supports both Basic and Advanced Google Cloud Translation APIs and various CSV formats.
+
+With --new-columns the source cells are kept and the translations are written
+to new columns, either appended at the end of the rows or inserted after each
+source column. The target may then list several languages (-t uk,de,fr), which
+gives one new column per language and source column.
+
+Rows may name their own languages: --source-column and --target-column give
+the columns holding the source and target language of each row (an empty
+cell falls back to -s or -t). Rows with the same languages are translated
+together, and rows already in their target language are copied. With
+--detected-column the source language of each row, as detected by the API
+when it is not known, is written to a new last column. With --target-column
+and --new-columns, {lang} in --column-name stands for "translated" (e.g.
+title_translated), as the column holds translations into the languages of
+all rows.
+
+--context-column names a column (e.g. notes) giving the context of each row.
+The column is not translated; the cells of a row with a context are sent as
+HTML preceded by it, marked translate="no" and removed from the translation,
+so "Save" next to "button" and "Save" next to "money" are translated apart.
+
+Columns are given by number (2), letter (B) or, with --header, name or glob
+(desc_*), and as ranges (B:F, 2-6) or exclusions (!C). --auto-columns keeps,
+among the given columns or the whole row, those whose values look like
+natural language, skipping IDs, SKUs, numbers, dates, URLs and e-mails; the
+choice is printed, and --preview prints it without translating.
+
+The delimiter is detected when --csv-delimiter is not given, and the line
+breaks and the UTF-8 byte order mark of the input are kept. Files with bare
+quotes or rows of different lengths are read with --lazy-quotes and
+--variable-fields; --keep-comments keeps comment and blank lines in place.
+
+--where decides for every cell whether it is translated, with comparisons of
+the columns of its row and of the cell itself (value, column):
+
+  status == published && lang != uk
+  title =~ /^Draft/ or not notes
+  column != sku and value !~ /^[A-Z]+-\d+$/
+
+The left side of a comparison is a column, the right side a word, a quoted
+string, a number or a /regular expression/; <, <=, > and >= compare numbers
+when both sides are numbers, and strings otherwise. Cells left out, and cells without letters (empty or numeric), are copied
+without being sent for translation.
+
+--max-length column=length (e.g. title=40, 'desc_*=160') sets the maximum
+number of characters of the translations of a column. Translations that are
+longer are kept but reported, with their row, column and language, on the
+standard error and, with --length-report, in a CSV report.
+
+Rows are read, translated and written in batches, so files of any size are
+translated in constant memory, and the output holds every row translated
+before an error. The width of the rows and the choice of --auto-columns come
+from the header and the first rows; with --new-columns or --detected-column,
+a wider row further down is an error.`,
 	// Run: func(cmd *cobra.Command, args []string) {
 	// 	fmt.Println("csv called")
 	// },
@@ -43,39 +109,113 @@ Supports both Basic and Advanced Google Cloud Translation APIs and various CSV f
 
 		defer close(shutdownCh) // Signal indicator() to terminate
 
-		csv, err := readCSVToSlice(inputFile, false, csvDelimiter, csvComment)
+		dialect, err := newCSVDialect()
+		if err != nil {
+			return err
+		}
+		in, err := openInput(inputFile)
 		if err != nil {
 			return fmt.Errorf("failed to read CSV file: %v", err)
 		}
+		defer in.Close()
+		reader := newCSVReader(in, dialect)
 
-		colNumbers, err := decodeColNumbers(csvColumn, len(csv[0]))
+		// The first batch gives the header, the width of the rows and the
+		// sample looked at by --auto-columns
+		batch, err := readCSVBatch(reader, csvAutoSample+1)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to read CSV file: %v", err)
 		}
-		nCols := len(colNumbers)
-		for i, row := range csv {
-			if nCols == 0 {
-				strOut, err := translateEx(row, useAdvanced)
-				if err != nil {
-					return fmt.Errorf("failed to translate text: %v", err)
-				}
-				csv[i] = strOut
-			} else {
-				strInp := make([]string, 0, nCols)
-				for _, v := range colNumbers {
-					strInp = append(strInp, row[v-1])
-				}
-				strOut, err := translateEx(strInp, useAdvanced)
-				if err != nil {
-					return fmt.Errorf("failed to translate text: %v", err)
-				}
-				for k, v := range strOut {
-					row[colNumbers[k]-1] = v
-				}
+		var csv [][]string
+		var headerRec *csvRecord
+		for _, rec := range batch {
+			if rec.fields == nil {
+				continue
+			}
+			if csvHeader && headerRec == nil {
+				headerRec = rec
+				continue
 			}
+			csv = append(csv, rec.fields)
 		}
 
-		return writeSliceToCSV(outputFile, csv, nil, csvDelimiter)
+		// The header row is kept as it is and names the columns
+		plan := &csvPlan{}
+		var header []string
+		if headerRec != nil {
+			header = headerRec.fields
+			plan.row = 1
+		}
+		for _, row := range csv {
+			plan.width = max(plan.width, len(row))
+		}
+		plan.width = max(plan.width, len(header))
+
+		// An empty file is copied
+		if plan.width > 0 {
+			if err := plan.setup(header, csv); err != nil {
+				return err
+			}
+			if csvPreview {
+				return nil
+			}
+		}
+
+		// Ensure the output directory exists
+		if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %v", err)
+		}
+		out, err := createOutput(outputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create the output file: %v", err)
+		}
+		defer out.Close()
+		// A byte order mark is only kept in UTF-8
+		if !outputIsUTF8() {
+			dialect.BOM = false
+		}
+		writer, err := newCSVWriter(out, dialect)
+		if err != nil {
+			return fmt.Errorf("failed to write to the output file: %v", err)
+		}
+
+		// Translations exceeding --max-length are reported as they come
+		if csvLengthReport != "" {
+			report, err := createOutput(csvLengthReport)
+			if err != nil {
+				return fmt.Errorf("failed to create the length report: %v", err)
+			}
+			defer report.Close()
+			if plan.report, err = newCSVWriter(report, &csvDialect{Comma: ','}); err != nil {
+				return fmt.Errorf("failed to write the length report: %v", err)
+			}
+			err = plan.report.Write(&csvRecord{fields: []string{"row", "column", "letter", "language", "length", "limit", "text"}})
+			if err != nil {
+				return fmt.Errorf("failed to write the length report: %v", err)
+			}
+		}
+
+		if headerRec != nil {
+			headerRec.fields = plan.headerRow(header)
+		}
+		err = streamCSV(reader, writer, batch, func(batch []*csvRecord) error {
+			if plan.width == 0 {
+				return nil
+			}
+			return plan.translate(batch, headerRec)
+		})
+		if err != nil {
+			return err
+		}
+
+		if err := out.Close(); err != nil {
+			return fmt.Errorf("failed to write to the output file: %v", err)
+		}
+		if plan.violations > 0 {
+			fmt.Fprintf(os.Stderr, "%d translations exceed their maximum length\n", plan.violations)
+		}
+
+		return nil
 	},
 }
 
@@ -91,33 +231,681 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// csvCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	csvCmd.Flags().StringSliceVarP(&csvColumn, "column", "l", []string{}, "One or many columns number to translate (can be specified multiple times). Numeration starts from '1' or 'A'")
-	csvCmd.Flags().StringVarP(&csvDelimiter, "csv-delimiter", "", "", "Delimiter for CSV files")
+	csvCmd.Flags().StringSliceVarP(&csvColumn, "column", "l", []string{}, "One or many columns number to translate (can be specified multiple times). Numeration starts from '1' or 'A'. Ranges (B:F, 2-6) and exclusions (!C) are accepted; with --header, columns can also be given by name or glob, e.g. 'desc_*'")
+	csvCmd.Flags().BoolVarP(&csvAutoColumns, "auto-columns", "", false, "Translate only the columns whose values look like natural language")
+	csvCmd.Flags().BoolVarP(&csvPreview, "preview", "", false, "Print the columns that would be translated and exit")
+	csvCmd.Flags().StringVarP(&csvWhere, "where", "", "", "Translate only the cells for which the filter expression holds, e.g. 'status == published'")
+	csvCmd.Flags().BoolVarP(&csvHeader, "header", "", false, "Keep the first row untranslated and use it for column names")
+	csvCmd.Flags().StringVarP(&csvNewColumns, "new-columns", "", "", "Write translations to new columns instead of overwriting: append (at the end) or insert (after each source column)")
+	csvCmd.Flags().StringVarP(&csvColumnName, "column-name", "", "{column}_{lang}", "Header of the new columns; {column} is the source column name, {lang} the target language, or 'translated' with --target-column")
+	csvCmd.Flags().StringVarP(&csvSourceColumn, "source-column", "", "", "Column holding the source language of each row")
+	csvCmd.Flags().StringVarP(&csvTargetColumn, "target-column", "", "", "Column holding the target language of each row")
+	csvCmd.Flags().StringVarP(&csvContextColumn, "context-column", "", "", "Column giving the context of each row; it is sent along with the cells of the row but not translated")
+	csvCmd.Flags().StringVarP(&csvDetectedColumn, "detected-column", "", "", "Header of a new last column receiving the source language of each row")
+	csvCmd.Flags().StringSliceVarP(&csvMaxLength, "max-length", "", []string{}, "Maximum length of the translations of a column, as column=length (can be specified multiple times)")
+	csvCmd.Flags().StringVarP(&csvLengthReport, "length-report", "", "", "CSV file receiving the translations exceeding --max-length")
+	csvCmd.Flags().StringVarP(&csvDelimiter, "csv-delimiter", "", "", "Delimiter for CSV files (detected when not given; '\\t' for tab)")
 	csvCmd.Flags().StringVarP(&csvComment, "csv-comment", "", "", "Comment character for CSV files")
+	csvCmd.Flags().BoolVarP(&csvKeepComments, "keep-comments", "", false, "Keep comment and blank lines in their original positions")
+	csvCmd.Flags().BoolVarP(&csvLazyQuotes, "lazy-quotes", "", false, "Accept bare quotes in fields")
+	csvCmd.Flags().BoolVarP(&csvVariableFields, "variable-fields", "", false, "Accept rows with different numbers of fields")
+	addEncodingFlags(csvCmd)
+}
+
+// newCSVDialect returns the dialect given by the flags of the csv command
+func newCSVDialect() (*csvDialect, error) {
+	dialect := &csvDialect{
+		LazyQuotes:     csvLazyQuotes,
+		VariableFields: csvVariableFields,
+		KeepComments:   csvKeepComments,
+	}
+	for _, char := range []struct {
+		r    *rune
+		flag string
+		text string
+	}{{&dialect.Comma, "--csv-delimiter", csvDelimiter}, {&dialect.Comment, "--csv-comment", csvComment}} {
+		if char.text == `\t` {
+			char.text = "\t"
+		}
+		runes := []rune(char.text)
+		if len(runes) > 1 {
+			return nil, fmt.Errorf("%v must be a single character: %q", char.flag, char.text)
+		}
+		if len(runes) == 1 {
+			*char.r = runes[0]
+		}
+	}
+	if dialect.Comma != 0 && dialect.Comma == dialect.Comment {
+		return nil, fmt.Errorf("--csv-delimiter and --csv-comment must differ")
+	}
+
+	return dialect, nil
 }
 
-func decodeColNumbers(csvColumn []string, csvWidth int) ([]int, error) {
-	var colNumbers []int = make([]int, 0, len(csvColumn))
+// **************************************************************************
+// decodeColNumbers converts the --column values into 1-based column numbers.
+// A value is a single column, a range of columns (B:F, 2-6, title:price) or,
+// prefixed with !, a column or range to leave out; exclusions alone leave
+// out columns of the whole row. With a header row, a value is first looked
+// up as a column name, then as a glob pattern over the names (desc_*), and
+// only then read as a number or a letter. Unknown names are reported with
+// the list of available columns.
+// --------------------------------------------------------------------------
+func decodeColNumbers(csvColumn []string, header []string, csvWidth int) ([]int, error) {
+	var include, exclude []int
+	add := func(list *[]int, colNumber int) {
+		if !slices.Contains(*list, colNumber) {
+			*list = append(*list, colNumber)
+		}
+	}
 	for _, col := range csvColumn {
-		col = strings.ToUpper(strings.TrimSpace(col))
-		var colNumber int
-		var err error
-		if (col[0] >= 'A') && (col[0] <= 'Z') {
-			colNumber = titleToNumber(col)
+		col = strings.TrimSpace(col)
+		list := &include
+		if strings.HasPrefix(col, "!") && !slices.Contains(header, col) {
+			col, list = strings.TrimSpace(col[1:]), &exclude
+		}
+		if col == "" {
+			return nil, fmt.Errorf("empty column reference")
+		}
+
+		cols, err := decodeColumnRef(col, header, csvWidth)
+		if err != nil {
+			return nil, err
+		}
+		for _, colNumber := range cols {
+			add(list, colNumber)
+		}
+	}
+	if len(exclude) == 0 {
+		return include, nil
+	}
+
+	if len(include) == 0 {
+		for c := 1; c <= csvWidth; c++ {
+			include = append(include, c)
+		}
+	}
+	colNumbers := make([]int, 0, len(include))
+	for _, colNumber := range include {
+		if !slices.Contains(exclude, colNumber) {
+			colNumbers = append(colNumbers, colNumber)
+		}
+	}
+	if len(colNumbers) == 0 {
+		return nil, fmt.Errorf("all columns are excluded")
+	}
+
+	return colNumbers, nil
+}
+
+// decodeColumnRef returns the columns of a name, glob pattern, range or
+// single column
+func decodeColumnRef(col string, header []string, csvWidth int) ([]int, error) {
+	if header != nil {
+		if matches, err := matchHeader(header, col); err != nil || len(matches) > 0 {
+			return matches, err
+		}
+	}
+
+	if i := strings.IndexAny(col, ":-"); i > 0 && i < len(col)-1 {
+		lo, err := decodeColumn(strings.TrimSpace(col[:i]), header, csvWidth)
+		if err != nil {
+			return nil, err
+		}
+		hi, err := decodeColumn(strings.TrimSpace(col[i+1:]), header, csvWidth)
+		if err != nil {
+			return nil, err
+		}
+		if lo > hi {
+			return nil, fmt.Errorf("invalid column range: %v", col)
+		}
+		cols := make([]int, 0, hi-lo+1)
+		for c := lo; c <= hi; c++ {
+			cols = append(cols, c)
+		}
+		return cols, nil
+	}
+
+	colNumber, err := decodeColumn(col, header, csvWidth)
+	if err != nil {
+		return nil, err
+	}
+
+	return []int{colNumber}, nil
+}
+
+// decodeColumn returns the number of a single column given by name, number
+// or letter
+func decodeColumn(col string, header []string, csvWidth int) (int, error) {
+	for i, name := range header {
+		if name == col {
+			return i + 1, nil
+		}
+	}
+	for i, name := range header {
+		if strings.EqualFold(name, col) {
+			return i + 1, nil
+		}
+	}
+
+	name := col
+	col = strings.ToUpper(col)
+	var colNumber int
+	var err error
+	if col != "" && (col[0] >= 'A') && (col[0] <= 'Z') {
+		colNumber = titleToNumber(col)
+	} else {
+		colNumber, err = strconv.Atoi(col)
+		if err != nil {
+			if header != nil {
+				return 0, fmt.Errorf("unknown column %q, available columns: %v", name, strings.Join(header, ", "))
+			}
+			return 0, fmt.Errorf("invalid column number: %v", col)
+		}
+	}
+
+	if (colNumber < 1) || (colNumber > csvWidth) {
+		if header != nil {
+			return 0, fmt.Errorf("unknown column %q, available columns: %v", name, strings.Join(header, ", "))
+		}
+		return 0, fmt.Errorf("column number is out of range: %v", col)
+	}
+
+	return colNumber, nil
+}
+
+// matchHeader returns the numbers of the columns whose name is col, or
+// matches col as a glob pattern. Names are compared case-insensitively when
+// no name matches exactly.
+func matchHeader(header []string, col string) ([]int, error) {
+	for i, name := range header {
+		if name == col {
+			return []int{i + 1}, nil
+		}
+	}
+	for i, name := range header {
+		if strings.EqualFold(name, col) {
+			return []int{i + 1}, nil
+		}
+	}
+
+	if !strings.ContainsAny(col, "*?[") {
+		return nil, nil
+	}
+	var matches []int
+	for i, name := range header {
+		ok, err := path.Match(col, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column pattern %q: %v", col, err)
+		}
+		if ok {
+			matches = append(matches, i+1)
+		}
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no column matches %q, available columns: %v", col, strings.Join(header, ", "))
+	}
+
+	return matches, nil
+}
+
+// csvCell returns the cell of a row in a 1-based column, or "" for short rows
+func csvCell(row []string, colNumber int) string {
+	if colNumber > len(row) {
+		return ""
+	}
+
+	return row[colNumber-1]
+}
+
+// csvPlan is how the csv command translates rows
+type csvPlan struct {
+	width          int         // Number of columns
+	colNumbers     []int       // Columns to translate
+	srcCol, tgtCol int         // Columns naming the languages of each row, or 0
+	ctxCol         int         // Column giving the context of each row, or 0
+	header         []string    // Names of the columns, or nil
+	limits         map[int]int // Maximum length of the translations of columns
+	report         *csvWriter  // Report of the translations too long, or nil
+	violations     int         // Number of translations too long
+	targets        []string    // Target languages
+	filter         csvFilter   // Cells to translate, or nil for all
+	row            int         // Number of rows translated, for error messages
+}
+
+// **************************************************************************
+// setup chooses the columns to translate, given the header (or nil) and the
+// first rows of the file, and checks the options of the command.
+// --------------------------------------------------------------------------
+func (p *csvPlan) setup(header []string, sample [][]string) error {
+	p.header = header
+	colNumbers, err := decodeColNumbers(csvColumn, header, p.width)
+	if err != nil {
+		return err
+	}
+
+	// The languages and the context of each row may come from its own
+	// cells
+	for _, lang := range []struct {
+		col    *int
+		flag   string
+		column string
+	}{
+		{&p.srcCol, "--source-column", csvSourceColumn},
+		{&p.tgtCol, "--target-column", csvTargetColumn},
+		{&p.ctxCol, "--context-column", csvContextColumn},
+	} {
+		if lang.column == "" {
+			continue
+		}
+		cols, err := decodeColNumbers([]string{lang.column}, header, p.width)
+		if err != nil {
+			return fmt.Errorf("%v: %v", lang.flag, err)
+		}
+		if len(cols) != 1 {
+			return fmt.Errorf("%v: %q names %d columns", lang.flag, lang.column, len(cols))
+		}
+		*lang.col = cols[0]
+	}
+
+	// Several targets are only possible when the translations go to new
+	// columns, one per target language
+	p.targets = strings.Split(targetLang, ",")
+	for i := range p.targets {
+		p.targets[i] = strings.TrimSpace(p.targets[i])
+	}
+	switch {
+	case len(p.targets) > 1 && p.tgtCol > 0:
+		return fmt.Errorf("several target languages cannot be combined with --target-column")
+	case len(p.targets) > 1 && csvNewColumns == "":
+		return fmt.Errorf("several target languages require --new-columns")
+	case csvNewColumns != "" && csvNewColumns != csvAppend && csvNewColumns != csvInsert:
+		return fmt.Errorf("unknown --new-columns mode %q: use append or insert", csvNewColumns)
+	}
+
+	// Without --column every column is translated, except those naming
+	// the languages or giving the context
+	if len(colNumbers) == 0 {
+		for c := 1; c <= p.width; c++ {
+			if c != p.srcCol && c != p.tgtCol && c != p.ctxCol {
+				colNumbers = append(colNumbers, c)
+			}
+		}
+	}
+
+	// The automatic choice keeps the columns that look like natural
+	// language, and is always reported
+	var skipped map[int]string
+	if csvAutoColumns {
+		colNumbers, skipped = autoColumns(sample, colNumbers)
+	}
+	if csvAutoColumns || csvPreview {
+		fmt.Fprint(os.Stderr, previewColumns(header, colNumbers, skipped))
+	}
+	p.colNumbers = colNumbers
+
+	if csvWhere != "" {
+		if p.filter, err = parseCSVFilter(csvWhere, header, p.width); err != nil {
+			return err
+		}
+	}
+
+	return p.parseLimits()
+}
+
+// **************************************************************************
+// parseLimits reads the --max-length rules, column=length, where the column
+// may also be a glob pattern or a range.
+// --------------------------------------------------------------------------
+func (p *csvPlan) parseLimits() error {
+	p.limits = make(map[int]int)
+	for _, rule := range csvMaxLength {
+		i := strings.LastIndex(rule, "=")
+		if i < 0 {
+			return fmt.Errorf("invalid --max-length rule %q: use column=length", rule)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(rule[i+1:]))
+		if err != nil || limit < 1 {
+			return fmt.Errorf("invalid --max-length rule %q: the length must be a positive number", rule)
+		}
+		cols, err := decodeColumnRef(strings.TrimSpace(rule[:i]), p.header, p.width)
+		if err != nil {
+			return fmt.Errorf("--max-length: %v", err)
+		}
+		for _, c := range cols {
+			if !slices.Contains(p.colNumbers, c) {
+				return fmt.Errorf("--max-length: column %v is not translated", csvColumnLabel(p.header, c))
+			}
+			p.limits[c] = limit
+		}
+	}
+
+	return nil
+}
+
+// headerRow returns the header row of the output
+func (p *csvPlan) headerRow(header []string) []string {
+	if csvNewColumns != "" {
+		// With the target languages in a column, one new column receives
+		// the translations into all of them
+		langs := p.targets
+		if p.tgtCol > 0 {
+			langs = []string{"translated"}
+		}
+		names := make([][]string, len(p.colNumbers))
+		for k, v := range p.colNumbers {
+			for _, lang := range langs {
+				r := strings.NewReplacer("{column}", csvCell(header, v), "{lang}", lang)
+				names[k] = append(names[k], r.Replace(csvColumnName))
+			}
+		}
+		header = addCSVColumns(header, p.width, p.colNumbers, names, csvNewColumns)
+	}
+	if csvDetectedColumn != "" {
+		header = append(header, csvDetectedColumn)
+	}
+
+	return header
+}
+
+// **************************************************************************
+// translate translates the rows of a batch of records in place, leaving out
+// the header record (or nil).
+// --------------------------------------------------------------------------
+func (p *csvPlan) translate(batch []*csvRecord, headerRec *csvRecord) error {
+	var recs []*csvRecord
+	var csv [][]string
+	for _, rec := range batch {
+		if rec.fields != nil && rec != headerRec {
+			recs = append(recs, rec)
+			csv = append(csv, rec.fields)
+		}
+	}
+	first := p.row + 1
+
+	// New columns are placed after the width of the first rows, so wider
+	// rows further down would put them under the wrong header
+	if csvNewColumns != "" || csvDetectedColumn != "" {
+		for i, row := range csv {
+			if len(row) > p.width {
+				return fmt.Errorf("row %d has %d columns, more than the %d of the first rows", first+i, len(row), p.width)
+			}
+		}
+	}
+
+	values, detected, err := p.translateRows(csv)
+	if err != nil {
+		return err
+	}
+	if err := p.checkLengths(first, csv, values); err != nil {
+		return err
+	}
+
+	for i, row := range csv {
+		if csvNewColumns == "" {
+			for k, c := range p.colNumbers {
+				if c <= len(row) {
+					row[c-1] = values[i][k][0]
+				}
+			}
 		} else {
-			colNumber, err = strconv.Atoi(col)
+			row = addCSVColumns(row, p.width, p.colNumbers, values[i], csvNewColumns)
+		}
+
+		// The source language of each row goes to a last column
+		if csvDetectedColumn != "" {
+			row = append(row, detected[i])
+		}
+		recs[i].fields = row
+	}
+
+	return nil
+}
+
+// **************************************************************************
+// checkLengths reports the translations longer, in characters, than the
+// limit of their column: on the standard error and, with --length-report,
+// as rows of the report. first is the number of the first row.
+// --------------------------------------------------------------------------
+func (p *csvPlan) checkLengths(first int, csv [][]string, values [][][]string) error {
+	for i, row := range csv {
+		for k, c := range p.colNumbers {
+			limit, ok := p.limits[c]
+			if !ok {
+				continue
+			}
+			for t, text := range values[i][k] {
+				n := utf8.RuneCountInString(text)
+				if n <= limit {
+					continue
+				}
+
+				lang := csvCell(p.targets, t+1)
+				if p.tgtCol > 0 && strings.TrimSpace(csvCell(row, p.tgtCol)) != "" {
+					lang = strings.TrimSpace(csvCell(row, p.tgtCol))
+				}
+				p.violations++
+				fmt.Fprintf(os.Stderr, "row %d, column %v, %v: %d characters, limit %d\n",
+					first+i, csvColumnLabel(p.header, c), lang, n, limit)
+				if p.report == nil {
+					continue
+				}
+				err := p.report.Write(&csvRecord{fields: []string{
+					strconv.Itoa(first + i), csvCell(p.header, c), csvColumnLabel(nil, c),
+					lang, strconv.Itoa(n), strconv.Itoa(limit), text,
+				}})
+				if err != nil {
+					return fmt.Errorf("failed to write the length report: %v", err)
+				}
+			}
+		}
+	}
+	if p.report != nil {
+		if err := p.report.Flush(); err != nil {
+			return fmt.Errorf("failed to write the length report: %v", err)
+		}
+	}
+
+	return nil
+}
+
+// csvLangPair is the source and target language of a group of rows
+type csvLangPair struct {
+	source, target string
+}
+
+// **************************************************************************
+// translateRows translates the cells of the chosen columns in every row and
+// returns values[i][k][t], the translation of cell colNumbers[k] of row i
+// into the target t, together with the source language of every row.
+//
+// The languages of a row are read from its cells in srcCol and tgtCol (when
+// not 0), falling back to sourceLang and the targets. Rows sharing the same
+// pair of languages are translated in batches, where identical cells are
+// translated once unless the context column gives them different contexts,
+// and cells with a context are sent preceded by it (see csvContextHTML).
+// Rows whose source language is their target language are copied, and so
+// are cells without letters (empty or numeric) and cells left out by the
+// filter. Errors name the number of a row in the file.
+// --------------------------------------------------------------------------
+func (p *csvPlan) translateRows(csv [][]string) ([][][]string, []string, error) {
+	colNumbers, srcCol, tgtCol := p.colNumbers, p.srcCol, p.tgtCol
+	first := p.row + 1
+	p.row += len(csv)
+
+	values := make([][][]string, len(csv))
+	for i := range csv {
+		values[i] = make([][]string, len(colNumbers))
+	}
+	detected := make([]string, len(csv))
+
+	// The language globals are set for each group and restored at the end
+	defer func(source, target string) {
+		sourceLang, targetLang = source, target
+	}(sourceLang, targetLang)
+	source := sourceLang
+
+	for _, target := range p.targets {
+		var pairs []csvLangPair
+		rows := make(map[csvLangPair][]int)
+		for i, row := range csv {
+			pair := csvLangPair{source, target}
+			if srcCol > 0 && strings.TrimSpace(csvCell(row, srcCol)) != "" {
+				pair.source = strings.TrimSpace(csvCell(row, srcCol))
+			}
+			if tgtCol > 0 && strings.TrimSpace(csvCell(row, tgtCol)) != "" {
+				pair.target = strings.TrimSpace(csvCell(row, tgtCol))
+			}
+			if pair.target == "" {
+				return nil, nil, fmt.Errorf("row %d: no target language", first+i)
+			}
+			if _, ok := rows[pair]; !ok {
+				pairs = append(pairs, pair)
+			}
+			rows[pair] = append(rows[pair], i)
+		}
+
+		for _, pair := range pairs {
+			same := strings.EqualFold(pair.source, pair.target)
+			// Identical cells are translated once, unless their context
+			// differs
+			var texts, contexts []string
+			var cells [][3]int // Row, column and index in texts
+			seen := make(map[segment]int)
+			for _, i := range rows[pair] {
+				if pair.source != "auto" {
+					detected[i] = pair.source
+				}
+				for k, c := range colNumbers {
+					text := csvCell(csv[i], c)
+					values[i][k] = append(values[i][k], text)
+					if same || !hasLetters(text) || (p.filter != nil && !p.filter(csv[i], c)) {
+						continue
+					}
+					seg := segment{Text: text}
+					if p.ctxCol > 0 {
+						seg.Context = strings.TrimSpace(csvCell(csv[i], p.ctxCol))
+					}
+					n, ok := seen[seg]
+					if !ok {
+						n = len(texts)
+						seen[seg] = n
+						texts = append(texts, text)
+						contexts = append(contexts, seg.Context)
+					}
+					cells = append(cells, [3]int{i, k, n})
+				}
+			}
+			if len(texts) == 0 {
+				continue
+			}
+
+			sourceLang, targetLang = pair.source, pair.target
+			strOut, langs, err := translateWithContext(texts, contexts)
 			if err != nil {
-				return nil, fmt.Errorf("invalid column number: %v", col)
+				return nil, nil, fmt.Errorf("failed to translate from %v to %v: %v", pair.source, pair.target, err)
+			}
+			for _, cell := range cells {
+				i, k, n := cell[0], cell[1], cell[2]
+				values[i][k][len(values[i][k])-1] = strOut[n]
+				if detected[i] == "" {
+					detected[i] = langs[n]
+				}
+			}
+		}
+	}
+
+	return values, detected, nil
+}
+
+// translateWithContext translates texts like translateBatchedDetect; the
+// texts with a context are sent as HTML, preceded by their context
+func translateWithContext(texts, contexts []string) ([]string, []string, error) {
+	strOut := make([]string, len(texts))
+	langs := make([]string, len(texts))
+	for _, asHTML := range []bool{false, true} {
+		var indexes []int
+		var batch []string
+		for n, text := range texts {
+			if (contexts[n] != "") != asHTML {
+				continue
+			}
+			if asHTML {
+				text = csvContextHTML(text, contexts[n])
 			}
+			indexes = append(indexes, n)
+			batch = append(batch, text)
+		}
+		if len(batch) == 0 {
+			continue
 		}
 
-		if (colNumber < 1) || (colNumber > csvWidth) {
-			return nil, fmt.Errorf("column number is out of range: %v", col)
+		format := formatText
+		if asHTML {
+			format = formatHTML
+		}
+		translated, detected, err := translateBatchedDetect(batch, format)
+		if err != nil {
+			return nil, nil, err
+		}
+		for k, n := range indexes {
+			if asHTML {
+				translated[k] = csvStripContext(translated[k], texts[n])
+			}
+			strOut[n], langs[n] = translated[k], detected[k]
 		}
-		colNumbers = append(colNumbers, colNumber)
 	}
 
-	return colNumbers, nil
+	return strOut, langs, nil
+}
+
+// csvContextHTML returns a cell as HTML preceded by its context, which is
+// shown to the translation but marked not to be translated
+func csvContextHTML(text, context string) string {
+	return `<span translate="no">` + html.EscapeString(context) + ":</span> " +
+		strings.ReplaceAll(html.EscapeString(text), "\n", "<br>")
+}
+
+// csvStripContext removes the context added by csvContextHTML from the
+// translation of text, wherever the translation has moved it, and gives the
+// result the leading and trailing whitespace of text
+func csvStripContext(translated, text string) string {
+	if loc := reNoTranslateSpan.FindStringIndex(translated); loc != nil {
+		translated = translated[:loc[0]] + translated[loc[1]:]
+	}
+	core := strings.TrimSpace(text)
+	lead := text[:strings.Index(text, core)]
+
+	return lead + strings.TrimSpace(decodeHTMLText(translated)) + text[len(lead)+len(core):]
+}
+
+// **************************************************************************
+// addCSVColumns returns row, padded to width, with new cells for the columns
+// in colNumbers: values[k] holds the cells added for colNumbers[k], one per
+// target language. In append mode they follow the last column; in insert
+// mode they follow their source column.
+// --------------------------------------------------------------------------
+func addCSVColumns(row []string, width int, colNumbers []int, values [][]string, mode string) []string {
+	for len(row) < width {
+		row = append(row, "")
+	}
+
+	if mode == csvAppend {
+		for _, cells := range values {
+			row = append(row, cells...)
+		}
+		return row
+	}
+
+	out := make([]string, 0, len(row)+len(values))
+	for c, cell := range row {
+		out = append(out, cell)
+		if k := slices.Index(colNumbers, c+1); k >= 0 {
+			out = append(out, values[k]...)
+		}
+	}
+
+	return out
 }
 
 func titleToNumber(columnTitle string) int {
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestAddCSVColumns(t *testing.T) {
+	tests := []struct {
+		row    []string
+		width  int
+		mode   string
+		values [][]string
+		want   []string
+	}{
+		{[]string{"1", "a", "b"}, 3, csvAppend, [][]string{{"A"}}, []string{"1", "a", "b", "A"}},
+		{[]string{"1", "a"}, 3, csvAppend, [][]string{{"A"}}, []string{"1", "a", "", "A"}},
+		{[]string{"1", "a", "b"}, 3, csvAppend, [][]string{{"A", "A2"}, {"B", "B2"}}, []string{"1", "a", "b", "A", "A2", "B", "B2"}},
+		{[]string{"1", "a", "b"}, 3, csvInsert, [][]string{{"A"}, {"B"}}, []string{"1", "a", "A", "b", "B"}},
+		{[]string{"1", "a"}, 3, csvInsert, [][]string{{"A"}, {"B"}}, []string{"1", "a", "A", "", "B"}},
+	}
+
+	for _, tt := range tests {
+		got := addCSVColumns(slices.Clone(tt.row), tt.width, []int{2, 3}[:len(tt.values)], tt.values, tt.mode)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("addCSVColumns(%q, %d, %v) = %q, want %q", tt.row, tt.width, tt.mode, got, tt.want)
+		}
+	}
+}
+
+func TestCSVPlanWiderRow(t *testing.T) {
+	defer func(mode string) { csvNewColumns = mode }(csvNewColumns)
+	csvNewColumns = csvAppend
+
+	// Rows wider than the first ones are refused before being translated
+	p := &csvPlan{width: 2, colNumbers: []int{2}, targets: []string{"uk"}, row: 1100}
+	batch := []*csvRecord{{fields: []string{"1101", "hello", "extra"}}}
+	err := p.translate(batch, nil)
+	if err == nil || !strings.Contains(err.Error(), "row 1101 has 3 columns, more than the 2") {
+		t.Errorf("translate() error = %v, want one naming row 1101", err)
+	}
+}
+
+func TestDecodeColNumbers(t *testing.T) {
+	header := []string{"id", "title", "desc_en", "desc_fr", "price", "!note"}
+
+	tests := []struct {
+		cols   []string
+		header []string
+		want   []int
+	}{
+		{[]string{"2"}, nil, []int{2}},
+		{[]string{"B", "d"}, nil, []int{2, 4}},
+		{[]string{"B:D"}, nil, []int{2, 3, 4}},
+		{[]string{"2-4", "3"}, nil, []int{2, 3, 4}},
+		{[]string{"!A"}, nil, []int{2, 3, 4, 5, 6}},
+		{[]string{"!A:B", "!F"}, nil, []int{3, 4, 5}},
+		{[]string{"B:E", "!C"}, nil, []int{2, 4, 5}},
+		{[]string{"title"}, header, []int{2}},
+		{[]string{"TITLE"}, header, []int{2}},
+		{[]string{"desc_*"}, header, []int{3, 4}},
+		{[]string{"title:desc_fr"}, header, []int{2, 3, 4}},
+		{[]string{"!id", "!price"}, header, []int{2, 3, 4, 6}},
+		{[]string{"!note"}, header, []int{6}},
+		{[]string{"E"}, header, []int{5}},
+	}
+
+	for _, tt := range tests {
+		got, err := decodeColNumbers(tt.cols, tt.header, len(header))
+		if err != nil {
+			t.Errorf("decodeColNumbers(%q) error: %v", tt.cols, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("decodeColNumbers(%q) = %v, want %v", tt.cols, got, tt.want)
+		}
+	}
+}
+
+func TestDecodeColNumbersErrors(t *testing.T) {
+	header := []string{"id", "title"}
+
+	tests := []struct {
+		cols   []string
+		header []string
+		want   string
+	}{
+		{[]string{"7"}, nil, "out of range"},
+		{[]string{"0"}, nil, "out of range"},
+		{[]string{"?"}, nil, "invalid column number"},
+		{[]string{"B:A"}, nil, "invalid column range"},
+		{[]string{"!A", "!B"}, nil, "all columns are excluded"},
+		{[]string{" "}, nil, "empty column reference"},
+		{[]string{"name"}, header, `unknown column "name", available columns: id, title`},
+		{[]string{"x_*"}, header, `no column matches "x_*"`},
+	}
+
+	for _, tt := range tests {
+		_, err := decodeColNumbers(tt.cols, tt.header, 2)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("decodeColNumbers(%q) error = %v, want %q", tt.cols, err, tt.want)
+		}
+	}
+}
+
+func TestCSVPlanHeaderRow(t *testing.T) {
+	defer func(mode, name string) { csvNewColumns, csvColumnName = mode, name }(csvNewColumns, csvColumnName)
+	csvNewColumns, csvColumnName = csvAppend, "{column}_{lang}"
+
+	header := []string{"id", "title", "lang"}
+	p := &csvPlan{width: 3, colNumbers: []int{2}, targets: []string{"uk", "de"}}
+	if got := p.headerRow(slices.Clone(header)); !slices.Equal(got, []string{"id", "title", "lang", "title_uk", "title_de"}) {
+		t.Errorf("headerRow() = %q", got)
+	}
+
+	p = &csvPlan{width: 3, colNumbers: []int{2}, targets: []string{"en"}, tgtCol: 3}
+	if got := p.headerRow(slices.Clone(header)); !slices.Equal(got, []string{"id", "title", "lang", "title_translated"}) {
+		t.Errorf("headerRow() with a target column = %q", got)
+	}
+}
+
+func TestCSVContext(t *testing.T) {
+	if got, want := csvContextHTML("Save <all>\nnow", "button"), `<span translate="no">button:</span> Save &lt;all&gt;<br>now`; got != want {
+		t.Errorf("csvContextHTML() = %q, want %q", got, want)
+	}
+
+	tests := []struct {
+		translated string
+		text       string
+		want       string
+	}{
+		{`<span translate="no">button:</span> Зберегти`, "Save", "Зберегти"},
+		{`Зберегти <span translate="no">button:</span>`, "Save", "Зберегти"},
+		{`<span translate="no">money:</span> Заощадити &amp; <br>витратити`, " Save & \nspend ", " Заощадити & \nвитратити "},
+	}
+
+	for _, tt := range tests {
+		if got := csvStripContext(tt.translated, tt.text); got != tt.want {
+			t.Errorf("csvStripContext(%q, %q) = %q, want %q", tt.translated, tt.text, got, tt.want)
+		}
+	}
+}
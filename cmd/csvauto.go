@@ -0,0 +1,134 @@
+/*
+This file implements the automatic choice of the CSV columns to translate.
+Every value of a column is classified as natural language or as one of the
+kinds of data that is never translated (numbers, dates, URLs, e-mails,
+identifiers such as SKUs or UUIDs); a column is translated when most of its
+values look like natural language.
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Number of rows looked at when choosing the columns
+const csvAutoSample = 1000
+
+// Kinds of values that are never translated, with the patterns recognizing
+// them, in the order they are tried
+var csvValueKinds = []struct {
+	name string
+	re   *regexp.Regexp
+}{
+	{"numbers", regexp.MustCompile(`^[-+]?[$€£¥₴]?\s*\d[\d\s.,']*\s*(%|[$€£¥₴]|[A-Z]{3})?$`)},
+	{"dates", regexp.MustCompile(`^(\d{1,4}[-/.]\d{1,2}[-/.]\d{1,4}([ T]\d{1,2}:\d{2}\S*)?|\d{1,2}:\d{2}(:\d{2})?\S*)$`)},
+	{"URLs", regexp.MustCompile(`(?i)^((https?|ftp)://|www\.)\S+$`)},
+	{"e-mails", regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)},
+	{"identifiers", regexp.MustCompile(`^([[:alnum:]]*\d[[:alnum:]]*([-_./:#][[:alnum:]]+)*|[[:alnum:]]+([-_./:#][[:alnum:]]*\d[[:alnum:]]*)+|[[:alnum:]]+(_[[:alnum:]]+)+)$`)},
+	{"flags", regexp.MustCompile(`(?i)^(true|false|null|nil|n/a)$`)},
+}
+
+// **************************************************************************
+// csvValueKind returns the kind of a value from csvValueKinds, "text" for
+// natural language or "" for a blank value.
+// --------------------------------------------------------------------------
+func csvValueKind(value string) string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return ""
+	}
+	for _, kind := range csvValueKinds {
+		if kind.re.MatchString(value) {
+			return kind.name
+		}
+	}
+	if !hasLetters(value) {
+		return "numbers"
+	}
+
+	return "text"
+}
+
+// **************************************************************************
+// autoColumns returns the columns among candidates whose values mostly look
+// like natural language, judging by the first csvAutoSample rows. The other
+// candidates are returned in skipped with the most common kind of their
+// values ("empty" for columns without values).
+// --------------------------------------------------------------------------
+func autoColumns(csv [][]string, candidates []int) (chosen []int, skipped map[int]string) {
+	skipped = make(map[int]string)
+	sample := csv[:min(len(csv), csvAutoSample)]
+	for _, c := range candidates {
+		counts := make(map[string]int)
+		total := 0
+		for _, row := range sample {
+			if kind := csvValueKind(csvCell(row, c)); kind != "" {
+				counts[kind]++
+				total++
+			}
+		}
+
+		if total > 0 && counts["text"]*2 >= total {
+			chosen = append(chosen, c)
+			continue
+		}
+
+		skipped[c] = "empty"
+		most := 0
+		for kind, n := range counts {
+			if kind != "text" && (n > most || n == most && kind < skipped[c]) {
+				skipped[c], most = kind, n
+			}
+		}
+	}
+
+	return chosen, skipped
+}
+
+// csvColumnLabel names a column in messages: its letter and, with a header,
+// its name
+func csvColumnLabel(header []string, colNumber int) string {
+	letter := ""
+	for n := colNumber; n > 0; n = (n - 1) / 26 {
+		letter = string(rune('A'+(n-1)%26)) + letter
+	}
+	if colNumber > len(header) {
+		return letter
+	}
+
+	return fmt.Sprintf("%v (%v)", letter, header[colNumber-1])
+}
+
+// **************************************************************************
+// previewColumns describes the columns chosen for translation and, when
+// known, why the other ones were skipped.
+// --------------------------------------------------------------------------
+func previewColumns(header []string, colNumbers []int, skipped map[int]string) string {
+	labels := make([]string, 0, len(colNumbers))
+	for _, c := range colNumbers {
+		labels = append(labels, csvColumnLabel(header, c))
+	}
+	if len(labels) == 0 {
+		labels = append(labels, "none")
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Columns to translate: %v\n", strings.Join(labels, ", "))
+	if len(skipped) > 0 {
+		cols := make([]int, 0, len(skipped))
+		for c := range skipped {
+			cols = append(cols, c)
+		}
+		sort.Ints(cols)
+		labels = labels[:0]
+		for _, c := range cols {
+			labels = append(labels, fmt.Sprintf("%v: %v", csvColumnLabel(header, c), skipped[c]))
+		}
+		fmt.Fprintf(&sb, "Columns skipped: %v\n", strings.Join(labels, ", "))
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCSVValueKind(t *testing.T) {
+	tests := []struct {
+		value string
+		want  string
+	}{
+		{"", ""},
+		{"   ", ""},
+		{"Hello, world", "text"},
+		{"Привіт", "text"},
+		{"Red", "text"},
+		{"42", "numbers"},
+		{"-3.5", "numbers"},
+		{"1 234,50 €", "numbers"},
+		{"$19.99", "numbers"},
+		{"15%", "numbers"},
+		{"100 USD", "numbers"},
+		{"2024-01-31", "dates"},
+		{"31.01.2024 10:15", "dates"},
+		{"12:30", "dates"},
+		{"https://example.com/a?b=c", "URLs"},
+		{"www.example.com", "URLs"},
+		{"someone@example.com", "e-mails"},
+		{"SKU-12345", "identifiers"},
+		{"AB12", "identifiers"},
+		{"550e8400-e29b-41d4-a716-446655440000", "identifiers"},
+		{"user_name", "identifiers"},
+		{"true", "flags"},
+		{"N/A", "flags"},
+		{"+-*/", "numbers"},
+	}
+
+	for _, tt := range tests {
+		if got := csvValueKind(tt.value); got != tt.want {
+			t.Errorf("csvValueKind(%q) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestAutoColumns(t *testing.T) {
+	csv := [][]string{
+		{"1", "Red shoes", "SKU-1", "", "https://a.example"},
+		{"2", "Blue hat", "SKU-2", "", "https://b.example"},
+		{"3", "42", "SKU-3", "", "Nice"},
+	}
+
+	chosen, skipped := autoColumns(csv, []int{1, 2, 3, 4, 5})
+	if !slices.Equal(chosen, []int{2}) {
+		t.Errorf("autoColumns() chose %v, want [2]", chosen)
+	}
+	want := map[int]string{1: "numbers", 3: "identifiers", 4: "empty", 5: "URLs"}
+	for c, kind := range want {
+		if skipped[c] != kind {
+			t.Errorf("autoColumns() skipped column %d as %q, want %q", c, skipped[c], kind)
+		}
+	}
+}
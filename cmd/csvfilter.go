@@ -0,0 +1,316 @@
+/*
+This file implements the filter expressions of the csv command, which decide
+for every cell whether it is translated. An expression compares columns of
+the row with literals:
+
+	status == published && lang != uk
+	title =~ /^(Draft|WIP)/ || not notes
+	price > 100 and column != sku and value !~ /^[A-Z]+-\d+$/
+
+The left side of a comparison is a column (a name, letter or number, or a
+quoted name) or one of the words value and column, standing for the cell
+being decided and the name of its column; the right side is a literal (a
+word, a quoted string, a number or a /regular expression/). == and != compare
+strings, ignoring spaces around cells; <, <=, > and >= compare numbers when
+both sides are numbers, and strings byte by byte otherwise. A column alone is
+true when it is not empty. Conditions are combined with &&/and, ||/or, !/not
+and parentheses.
+*/
+package cmd
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// csvFilter tells whether the cell of a row in a 1-based column is translated
+type csvFilter func(row []string, colNumber int) bool
+
+// csvOperand returns a string from a cell of a row
+type csvOperand func(row []string, colNumber int) string
+
+// csvToken is a token of a filter expression
+type csvToken struct {
+	kind string // "word", "string", "regexp", "op" or "" at the end
+	text string
+	pos  int
+}
+
+// csvFilterParser parses a filter expression
+type csvFilterParser struct {
+	tokens []csvToken
+	next   int
+	header []string
+	width  int
+}
+
+// **************************************************************************
+// parseCSVFilter compiles a filter expression. Columns are resolved against
+// the header (or nil) and the width of the rows.
+// --------------------------------------------------------------------------
+func parseCSVFilter(expr string, header []string, width int) (csvFilter, error) {
+	tokens, err := tokenizeCSVFilter(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", expr, err)
+	}
+
+	p := &csvFilterParser{tokens: tokens, header: header, width: width}
+	filter, err := p.parseOr()
+	if err == nil && p.peek().kind != "" {
+		err = fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos+1)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %v", expr, err)
+	}
+
+	return filter, nil
+}
+
+// Operators of filter expressions, longest first, and those comparing
+var (
+	csvFilterOps         = []string{"==", "!=", "=~", "!~", "<=", ">=", "&&", "||", "<", ">", "!", "(", ")"}
+	csvFilterComparisons = []string{"==", "!=", "=~", "!~", "<=", ">=", "<", ">"}
+)
+
+// tokenizeCSVFilter splits a filter expression into tokens
+func tokenizeCSVFilter(expr string) ([]csvToken, error) {
+	// Positions count characters rather than bytes
+	at := func(i int) int { return utf8.RuneCountInString(expr[:i]) }
+
+	var tokens []csvToken
+	for i := 0; i < len(expr); {
+		c, size := utf8.DecodeRuneInString(expr[i:])
+		switch {
+		case unicode.IsSpace(c):
+			i += size
+			continue
+		case c == '"' || c == '\'' || c == '/':
+			// Quoted strings and regular expressions end at the next
+			// unescaped delimiter; only the delimiter is unescaped
+			var sb strings.Builder
+			j := i + 1
+			for ; j < len(expr) && rune(expr[j]) != c; j++ {
+				if expr[j] == '\\' && j+1 < len(expr) && rune(expr[j+1]) == c {
+					j++
+				} else if expr[j] == '\\' && j+1 < len(expr) && c != '/' {
+					sb.WriteByte(expr[j])
+					j++
+				}
+				sb.WriteByte(expr[j])
+			}
+			if j == len(expr) {
+				return nil, fmt.Errorf("unterminated %c at position %d", c, at(i)+1)
+			}
+			kind := "string"
+			if c == '/' {
+				kind = "regexp"
+			}
+			tokens = append(tokens, csvToken{kind: kind, text: sb.String(), pos: at(i)})
+			i = j + 1
+			continue
+		}
+
+		op := ""
+		for _, o := range csvFilterOps {
+			if strings.HasPrefix(expr[i:], o) {
+				op = o
+				break
+			}
+		}
+		if op != "" {
+			tokens = append(tokens, csvToken{kind: "op", text: op, pos: at(i)})
+			i += len(op)
+			continue
+		}
+
+		// Words run up to a space, an operator or a quote
+		j := i
+		for j < len(expr) {
+			r, n := utf8.DecodeRuneInString(expr[j:])
+			if unicode.IsSpace(r) || strings.ContainsRune(`=!<>&|()"'`, r) {
+				break
+			}
+			j += n
+		}
+		if j == i {
+			return nil, fmt.Errorf("unexpected %q at position %d", expr[i:i+size], at(i)+1)
+		}
+		tokens = append(tokens, csvToken{kind: "word", text: expr[i:j], pos: at(i)})
+		i = j
+	}
+
+	return tokens, nil
+}
+
+// peek returns the next token, or a token of kind "" at the end
+func (p *csvFilterParser) peek() csvToken {
+	if p.next < len(p.tokens) {
+		return p.tokens[p.next]
+	}
+
+	return csvToken{text: "end of filter"}
+}
+
+// accept consumes the next token if it is one of the operators or keywords
+func (p *csvFilterParser) accept(texts ...string) bool {
+	t := p.peek()
+	for _, text := range texts {
+		if (t.kind == "op" && t.text == text) || (t.kind == "word" && strings.EqualFold(t.text, text)) {
+			p.next++
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *csvFilterParser) parseOr() (csvFilter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||", "or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string, c int) bool { return l(row, c) || right(row, c) }
+	}
+
+	return left, nil
+}
+
+func (p *csvFilterParser) parseAnd() (csvFilter, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&", "and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l := left
+		left = func(row []string, c int) bool { return l(row, c) && right(row, c) }
+	}
+
+	return left, nil
+}
+
+func (p *csvFilterParser) parseNot() (csvFilter, error) {
+	if p.accept("!", "not") {
+		inner, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(row []string, c int) bool { return !inner(row, c) }, nil
+	}
+	if p.accept("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("expected ) at position %d", p.peek().pos+1)
+		}
+		return inner, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison parses a comparison or a column alone
+func (p *csvFilterParser) parseComparison() (csvFilter, error) {
+	t := p.peek()
+	if t.kind != "word" && t.kind != "string" {
+		return nil, fmt.Errorf("expected a column at position %d, found %q", t.pos+1, t.text)
+	}
+	p.next++
+	left, err := p.operand(t)
+	if err != nil {
+		return nil, err
+	}
+
+	op := p.peek()
+	if op.kind != "op" || !slices.Contains(csvFilterComparisons, op.text) {
+		return func(row []string, c int) bool { return strings.TrimSpace(left(row, c)) != "" }, nil
+	}
+	p.next++
+
+	lit := p.peek()
+	if lit.kind != "word" && lit.kind != "string" && lit.kind != "regexp" {
+		return nil, fmt.Errorf("expected a value after %v at position %d", op.text, op.pos+1)
+	}
+	p.next++
+
+	switch op.text {
+	case "=~", "!~":
+		re, err := regexp.Compile(lit.text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression %q: %v", lit.text, err)
+		}
+		want := op.text == "=~"
+		return func(row []string, c int) bool { return re.MatchString(left(row, c)) == want }, nil
+	case "==", "!=":
+		want := op.text == "=="
+		return func(row []string, c int) bool { return (strings.TrimSpace(left(row, c)) == lit.text) == want }, nil
+	}
+
+	// Ordering compares numbers when both sides are numbers, strings
+	// otherwise
+	cmp := op.text
+	return func(row []string, c int) bool {
+		s := strings.TrimSpace(left(row, c))
+		x, errX := strconv.ParseFloat(s, 64)
+		y, errY := strconv.ParseFloat(lit.text, 64)
+		n := strings.Compare(s, lit.text)
+		if errX == nil && errY == nil {
+			n = 0
+			if x < y {
+				n = -1
+			} else if x > y {
+				n = 1
+			}
+		}
+		switch cmp {
+		case "<":
+			return n < 0
+		case "<=":
+			return n <= 0
+		case ">":
+			return n > 0
+		}
+		return n >= 0
+	}, nil
+}
+
+// operand returns the cell a column token stands for; the words value and
+// column stand for the cell being decided and the name of its column
+func (p *csvFilterParser) operand(t csvToken) (csvOperand, error) {
+	if t.kind == "word" {
+		switch strings.ToLower(t.text) {
+		case "value":
+			return func(row []string, c int) string { return csvCell(row, c) }, nil
+		case "column":
+			return func(row []string, c int) string {
+				if c <= len(p.header) {
+					return p.header[c-1]
+				}
+				return csvColumnLabel(nil, c)
+			}, nil
+		}
+	}
+
+	colNumber, err := decodeColumn(t.text, p.header, p.width)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(row []string, c int) string { return csvCell(row, colNumber) }, nil
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCSVFilter(t *testing.T) {
+	header := []string{"status", "lang", "title", "price", "sku"}
+	rows := [][]string{
+		{"published", "en", "Hello", "150", "AB-12"},
+		{"draft", "uk", "Draft: привіт", "99.5", ""},
+		{" хліб ", "uk", "WIP", "abc", "x"},
+	}
+
+	tests := []struct {
+		expr   string
+		column int
+		want   []bool
+	}{
+		{"status == published", 3, []bool{true, false, false}},
+		{"status != published", 3, []bool{false, true, true}},
+		{"status == published && lang != uk", 3, []bool{true, false, false}},
+		{"status == published || lang == uk", 3, []bool{true, true, true}},
+		{"status == хліб", 3, []bool{false, false, true}},
+		{"status == 'хліб'", 3, []bool{false, false, true}},
+		{"title =~ /^(Draft|WIP)/", 3, []bool{false, true, true}},
+		{"title !~ /привіт$/", 3, []bool{true, false, true}},
+		{"not sku", 3, []bool{false, true, false}},
+		{"!(sku) or price > 100", 3, []bool{true, true, true}},
+		{"price > 100", 3, []bool{true, false, true}},
+		{"price <= 99.5", 3, []bool{false, true, false}},
+		{"price >= abc", 3, []bool{false, false, true}},
+		{"D < 100 and A == draft", 3, []bool{false, true, false}},
+		{"4 > 100", 3, []bool{true, false, true}},
+		{"value == Hello", 3, []bool{true, false, false}},
+		{"column == title", 3, []bool{true, true, true}},
+		{"column != title", 2, []bool{true, true, true}},
+		{`title == "Draft: привіт"`, 3, []bool{false, true, false}},
+		{`"title" == 'WIP' AND NOT sku == ""`, 3, []bool{false, false, true}},
+	}
+
+	for _, tt := range tests {
+		filter, err := parseCSVFilter(tt.expr, header, len(header))
+		if err != nil {
+			t.Errorf("parseCSVFilter(%q) error: %v", tt.expr, err)
+			continue
+		}
+		for i, row := range rows {
+			if got := filter(row, tt.column); got != tt.want[i] {
+				t.Errorf("%q on row %d = %v, want %v", tt.expr, i+1, got, tt.want[i])
+			}
+		}
+	}
+}
+
+func TestParseCSVFilterErrors(t *testing.T) {
+	header := []string{"status", "lang"}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "expected a column"},
+		{"status ==", "expected a value after =="},
+		{"(status", "expected )"},
+		{"status == 'open", "unterminated '"},
+		{"status =~ /[/", "invalid regular expression"},
+		{"missing == x", "missing"},
+		{"status == x lang", `unexpected "lang" at position 13`},
+		{"status == хліб мука", `unexpected "мука" at position 16`},
+		{"&& status", "expected a column at position 1"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseCSVFilter(tt.expr, header, len(header))
+		if err == nil {
+			t.Errorf("parseCSVFilter(%q) succeeded, want an error", tt.expr)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseCSVFilter(%q) error %q, want it to contain %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestTokenizeCSVFilter(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"a==b", []string{"word:a", "op:==", "word:b"}},
+		{"a == хліб", []string{"word:a", "op:==", "word:хліб"}},
+		{`x == "say \"hi\""`, []string{"word:x", "op:==", `string:say "hi"`}},
+		{`x =~ /a\/b\d/`, []string{"word:x", "op:=~", `regexp:a/b\d`}},
+		{"!(a||b)&&c", []string{"op:!", "op:(", "word:a", "op:||", "word:b", "op:)", "op:&&", "word:c"}},
+	}
+
+	for _, tt := range tests {
+		tokens, err := tokenizeCSVFilter(tt.expr)
+		if err != nil {
+			t.Errorf("tokenizeCSVFilter(%q) error: %v", tt.expr, err)
+			continue
+		}
+		var got []string
+		for _, tok := range tokens {
+			got = append(got, tok.kind+":"+tok.text)
+		}
+		if strings.Join(got, " ") != strings.Join(tt.want, " ") {
+			t.Errorf("tokenizeCSVFilter(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+/*
+This file implements reading and writing of CSV files as they are found in the
+wild. The dialect of a file (delimiter, line breaks, byte order mark) is
+detected while reading and used again for writing, so that a translated file
+differs from its source only in the translated cells. Comment and blank lines,
+which encoding/csv skips, can be kept verbatim in their original positions.
+
+Files are streamed in batches of rows, so that files of any size can be
+translated in constant memory.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Number of bytes looked at when detecting the dialect of a file
+const csvSniffSize = 64 * 1024
+
+// Number of rows read, translated and written together
+const csvBatchRows = 500
+
+// Delimiters recognized when the delimiter is not given
+var csvDelimiters = []rune{',', ';', '\t', '|'}
+
+// UTF-8 byte order mark
+var utf8BOM = []byte{0xef, 0xbb, 0xbf}
+
+// csvDialect describes the layout of a CSV file. Comma, CRLF and BOM are
+// detected by newCSVReader when not set, and are used by newCSVWriter.
+type csvDialect struct {
+	Comma          rune // Field delimiter
+	Comment        rune // Start of comment lines, or 0
+	LazyQuotes     bool // Accept quotes in unquoted fields and bare quotes in quoted ones
+	VariableFields bool // Accept records with different numbers of fields
+	KeepComments   bool // Keep comment and blank lines
+	CRLF           bool // Lines end with \r\n
+	BOM            bool // The file starts with a UTF-8 byte order mark
+}
+
+// csvRecord is a record of a CSV file. Lines holds the comment and blank
+// lines preceding it, with their line breaks; the last record of a file may
+// consist of such lines only, and then has no fields.
+type csvRecord struct {
+	fields []string
+	lines  string
+}
+
+// recordingReader keeps the bytes read through it until they are dropped
+type recordingReader struct {
+	r   io.Reader
+	buf []byte
+}
+
+func (r *recordingReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.buf = append(r.buf, p[:n]...)
+
+	return n, err
+}
+
+// csvReader reads the records of a CSV file
+type csvReader struct {
+	r      *csv.Reader
+	src    *recordingReader
+	offset int64 // Input offset of src.buf[0]
+	line   int   // Number of lines read
+	keep   bool
+	done   bool
+}
+
+// **************************************************************************
+// newCSVReader returns a reader of the CSV records of in. The byte order
+// mark is removed, and the line breaks and, when d.Comma is 0, the delimiter
+// are detected from the beginning of the input and stored in d.
+// --------------------------------------------------------------------------
+func newCSVReader(in io.Reader, d *csvDialect) *csvReader {
+	br := bufio.NewReaderSize(in, csvSniffSize)
+	if head, _ := br.Peek(len(utf8BOM)); bytes.Equal(head, utf8BOM) {
+		br.Discard(len(utf8BOM))
+		d.BOM = true
+	}
+
+	head, _ := br.Peek(csvSniffSize)
+	if i := bytes.IndexByte(head, '\n'); i > 0 && head[i-1] == '\r' {
+		d.CRLF = true
+	}
+	if d.Comma == 0 {
+		d.Comma = sniffCSVDelimiter(head, d.Comment)
+	}
+
+	src := &recordingReader{r: br}
+	r := csv.NewReader(src)
+	r.Comma = d.Comma
+	r.Comment = d.Comment
+	r.LazyQuotes = d.LazyQuotes
+	if d.VariableFields {
+		r.FieldsPerRecord = -1
+	}
+
+	return &csvReader{r: r, src: src, keep: d.KeepComments}
+}
+
+// **************************************************************************
+// Read returns the next record, or io.EOF at the end of the input. With
+// KeepComments the comment and blank lines skipped before the record are
+// returned with it, and those at the end of the input as a last record
+// without fields.
+// --------------------------------------------------------------------------
+func (r *csvReader) Read() (*csvRecord, error) {
+	if r.done {
+		return nil, io.EOF
+	}
+
+	fields, err := r.r.Read()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	// The bytes consumed by the parser since the previous record
+	end := r.r.InputOffset()
+	raw := string(r.src.buf[:end-r.offset])
+	r.src.buf = r.src.buf[end-r.offset:]
+	r.offset = end
+
+	rec := &csvRecord{fields: fields}
+	if err == io.EOF {
+		r.done = true
+		if !r.keep || raw == "" {
+			return nil, io.EOF
+		}
+		rec.lines = raw
+		return rec, nil
+	}
+
+	// The record starts after the lines skipped by the parser
+	start, _ := r.r.FieldPos(0)
+	if r.keep {
+		skip := raw
+		for n := start - r.line - 1; n > 0; n-- {
+			i := strings.IndexByte(skip, '\n')
+			if i < 0 {
+				break
+			}
+			skip = skip[i+1:]
+		}
+		rec.lines = raw[:len(raw)-len(skip)]
+	}
+	r.line += strings.Count(raw, "\n")
+
+	return rec, nil
+}
+
+// **************************************************************************
+// sniffCSVDelimiter returns the delimiter of the CSV data starting with
+// head: among csvDelimiters, the one found the same number of times in every
+// record of head, outside quotes, and most often; failing that, the most
+// frequent one; and a comma when none is found.
+// --------------------------------------------------------------------------
+func sniffCSVDelimiter(head []byte, comment rune) rune {
+	// Number of each delimiter in the first records
+	var counts []map[rune]int
+	current := map[rune]int{}
+	quoted, empty := false, true
+	lines := bytes.SplitAfter(head, []byte("\n"))
+	if len(lines) > 1 && !bytes.HasSuffix(lines[len(lines)-1], []byte("\n")) {
+		lines = lines[:len(lines)-1] // Possibly cut by the sniff size
+	}
+	for _, line := range lines {
+		text := string(line)
+		if !quoted && (strings.TrimSpace(text) == "" || comment != 0 && strings.HasPrefix(text, string(comment))) {
+			continue
+		}
+		for _, c := range text {
+			switch {
+			case c == '"':
+				quoted = !quoted
+			case quoted:
+			case c == '\n':
+				counts = append(counts, current)
+				current, empty = map[rune]int{}, true
+				continue
+			default:
+				current[c]++
+			}
+			empty = false
+		}
+	}
+	if !empty {
+		counts = append(counts, current)
+	}
+	if len(counts) == 0 {
+		return ','
+	}
+
+	best, bestCount, consistent := ',', 0, false
+	for _, delim := range csvDelimiters {
+		n, same, total := counts[0][delim], true, 0
+		for _, record := range counts {
+			same = same && record[delim] == n
+			total += record[delim]
+		}
+		if total == 0 {
+			continue
+		}
+		if same && n > 0 && (!consistent || n > bestCount) {
+			best, bestCount, consistent = delim, n, true
+		} else if !consistent && total > bestCount {
+			best, bestCount = delim, total
+		}
+	}
+
+	return best
+}
+
+// csvWriter writes CSV records in a dialect
+type csvWriter struct {
+	w  *bufio.Writer
+	cw *csv.Writer
+}
+
+// **************************************************************************
+// newCSVWriter returns a writer of CSV records to out in the dialect d,
+// starting with the byte order mark when d.BOM is set.
+// --------------------------------------------------------------------------
+func newCSVWriter(out io.Writer, d *csvDialect) (*csvWriter, error) {
+	w := bufio.NewWriter(out)
+	if d.BOM {
+		if _, err := w.Write(utf8BOM); err != nil {
+			return nil, err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	if d.Comma != 0 {
+		cw.Comma = d.Comma
+	}
+	cw.UseCRLF = d.CRLF
+
+	return &csvWriter{w: w, cw: cw}, nil
+}
+
+// Write writes the lines preceding a record, verbatim, and then its fields
+func (w *csvWriter) Write(rec *csvRecord) error {
+	if rec.lines != "" {
+		w.cw.Flush()
+		if err := w.cw.Error(); err != nil {
+			return err
+		}
+		if _, err := w.w.WriteString(rec.lines); err != nil {
+			return err
+		}
+	}
+	if rec.fields == nil {
+		return nil
+	}
+
+	if err := w.cw.Write(rec.fields); err != nil {
+		return fmt.Errorf("error writing data: %v", err)
+	}
+
+	return nil
+}
+
+// Flush writes any buffered data to the underlying writer
+func (w *csvWriter) Flush() error {
+	w.cw.Flush()
+	if err := w.cw.Error(); err != nil {
+		return err
+	}
+
+	return w.w.Flush()
+}
+
+// **************************************************************************
+// readCSVBatch reads records until n of them have fields or the input ends,
+// and returns them; an empty batch means the end of the input.
+// --------------------------------------------------------------------------
+func readCSVBatch(r *csvReader, n int) ([]*csvRecord, error) {
+	var batch []*csvRecord
+	for rows := 0; rows < n; {
+		rec, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		batch = append(batch, rec)
+		if rec.fields != nil {
+			rows++
+		}
+	}
+
+	return batch, nil
+}
+
+// **************************************************************************
+// streamCSV passes the records of r through translate and writes them to w,
+// starting with the batch already read. Reading, translating and writing
+// run concurrently, one batch of csvBatchRows rows in each stage, so memory
+// use does not depend on the size of the file; every batch is flushed once
+// written, so the output holds all the rows translated before an error.
+// --------------------------------------------------------------------------
+func streamCSV(r *csvReader, w *csvWriter, first []*csvRecord, translate func([]*csvRecord) error) error {
+	done := make(chan struct{})
+	defer close(done)
+	read := make(chan []*csvRecord)
+	translated := make(chan []*csvRecord)
+	errs := make(chan error, 2)
+
+	go func() {
+		defer close(read)
+		for batch := first; len(batch) > 0; {
+			select {
+			case read <- batch:
+			case <-done:
+				return
+			}
+			var err error
+			if batch, err = readCSVBatch(r, csvBatchRows); err != nil {
+				errs <- fmt.Errorf("failed to read CSV file: %v", err)
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(translated)
+		for batch := range read {
+			if err := translate(batch); err != nil {
+				errs <- err
+				return
+			}
+			select {
+			case translated <- batch:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for batch := range translated {
+		for _, rec := range batch {
+			if err := w.Write(rec); err != nil {
+				return fmt.Errorf("failed to write to the output file: %v", err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to write to the output file: %v", err)
+		}
+	}
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+		return nil
+	}
+}
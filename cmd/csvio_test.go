@@ -0,0 +1,194 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// csvRows returns a CSV file of n rows and the same rows with the second
+// column upper-cased
+func csvRows(n int) (string, string) {
+	var in, want strings.Builder
+	for i := 1; i <= n; i++ {
+		fmt.Fprintf(&in, "%d,row %d\n", i, i)
+		fmt.Fprintf(&want, "%d,ROW %d\n", i, i)
+	}
+
+	return in.String(), want.String()
+}
+
+// upperSecond upper-cases the second field of the records of a batch
+func upperSecond(batch []*csvRecord) error {
+	for _, rec := range batch {
+		if len(rec.fields) > 1 {
+			rec.fields[1] = strings.ToUpper(rec.fields[1])
+		}
+	}
+
+	return nil
+}
+
+func TestStreamCSV(t *testing.T) {
+	for _, n := range []int{0, 1, csvBatchRows, csvBatchRows + 1, 3*csvBatchRows + 7} {
+		in, want := csvRows(n)
+		d := &csvDialect{}
+		r := newCSVReader(strings.NewReader(in), d)
+		var out bytes.Buffer
+		w, err := newCSVWriter(&out, d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := readCSVBatch(r, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := streamCSV(r, w, first, upperSecond); err != nil {
+			t.Fatalf("%d rows: %v", n, err)
+		}
+		if out.String() != want {
+			t.Errorf("%d rows: output differs from the input translated", n)
+		}
+	}
+}
+
+func TestStreamCSVError(t *testing.T) {
+	in, want := csvRows(3 * csvBatchRows)
+	d := &csvDialect{}
+	r := newCSVReader(strings.NewReader(in), d)
+	var out bytes.Buffer
+	w, err := newCSVWriter(&out, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The second batch fails; the first one is written
+	batches := 0
+	failure := errors.New("translation failed")
+	first, err := readCSVBatch(r, csvBatchRows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = streamCSV(r, w, first, func(batch []*csvRecord) error {
+		if batches++; batches == 2 {
+			return failure
+		}
+		return upperSecond(batch)
+	})
+	if err != failure {
+		t.Fatalf("streamCSV() error = %v, want %v", err, failure)
+	}
+
+	lines := strings.SplitAfter(want, "\n")
+	if got := out.String(); got != strings.Join(lines[:csvBatchRows], "") {
+		t.Errorf("output has %d lines, want the %d of the first batch", strings.Count(got, "\n"), csvBatchRows)
+	}
+}
+
+func TestSniffCSVDelimiter(t *testing.T) {
+	tests := []struct {
+		head    string
+		comment rune
+		want    rune
+	}{
+		{"", 0, ','},
+		{"just one field\n", 0, ','},
+		{"a,b,c\n1,2,3\n", 0, ','},
+		{"a;b;c\n1;2,5;3\n", 0, ';'},
+		{"a\tb\n1\t2\n", 0, '\t'},
+		{"a|b|c\n1|2|3\n", 0, '|'},
+		{"name;note\n\"x\";\"1,2,3,4\"\n", 0, ';'},
+		{"name;note\n\"multi\nline, with, commas\";x\n", 0, ';'},
+		{"# a,b,c,d,e\na;b\n1;2\n", '#', ';'},
+		{"\n\na;b\n\n1;2\n", 0, ';'},
+		{"a;b;c\n1;2;3\n4;5;6\n7;8;9,0,1,2,3", 0, ';'},
+		{"a,b;c\n1,2\n3;4;5;6\n", 0, ';'},
+	}
+
+	for _, tt := range tests {
+		if got := sniffCSVDelimiter([]byte(tt.head), tt.comment); got != tt.want {
+			t.Errorf("sniffCSVDelimiter(%q) = %q, want %q", tt.head, got, tt.want)
+		}
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		d    csvDialect
+		rows int
+	}{
+		{"comma", "a,b\n1,2\n", csvDialect{}, 2},
+		{"semicolon", "a;b\n1;\"x;y\"\n", csvDialect{}, 2},
+		{"tab", "a\tb\n1\t2\n", csvDialect{}, 2},
+		{"crlf", "a,b\r\n1,2\r\n", csvDialect{}, 2},
+		{"bom", "\ufeffa,b\n1,2\n", csvDialect{}, 2},
+		{"quoted newline", "a,b\n1,\"x\ny\"\n", csvDialect{}, 2},
+		{"comments", "# head\na,b\n\n# middle\n1,2\n# tail\n\n", csvDialect{Comment: '#', KeepComments: true}, 2},
+		{"comments crlf", "# head\r\na;b\r\n\r\n1;2\r\n# tail\r\n", csvDialect{Comment: '#', KeepComments: true}, 2},
+		{"blank lines", "a,b\n\n\n1,2\n", csvDialect{KeepComments: true}, 2},
+		{"variable fields", "a,b,c\n1\n1,2,3,4\n", csvDialect{VariableFields: true}, 3},
+	}
+
+	for _, tt := range tests {
+		d := tt.d
+		r := newCSVReader(strings.NewReader(tt.in), &d)
+		var out bytes.Buffer
+		w, err := newCSVWriter(&out, &d)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows := 0
+		for {
+			batch, err := readCSVBatch(r, 1)
+			if err != nil {
+				t.Fatalf("%v: %v", tt.name, err)
+			}
+			if len(batch) == 0 {
+				break
+			}
+			for _, rec := range batch {
+				if rec.fields != nil {
+					rows++
+				}
+				if err := w.Write(rec); err != nil {
+					t.Fatalf("%v: %v", tt.name, err)
+				}
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if rows != tt.rows {
+			t.Errorf("%v: read %d rows, want %d", tt.name, rows, tt.rows)
+		}
+		if out.String() != tt.in {
+			t.Errorf("%v: output %q, want %q", tt.name, out.String(), tt.in)
+		}
+	}
+}
+
+func TestCSVReaderDropsComments(t *testing.T) {
+	d := &csvDialect{Comment: '#'}
+	r := newCSVReader(strings.NewReader("# note\na,b\n\n1,2\n"), d)
+	var got []string
+	for {
+		rec, err := r.Read()
+		if err != nil {
+			break
+		}
+		if rec.lines != "" {
+			t.Errorf("record %q keeps lines %q without KeepComments", rec.fields, rec.lines)
+		}
+		got = append(got, strings.Join(rec.fields, ","))
+	}
+	if strings.Join(got, "|") != "a,b|1,2" {
+		t.Errorf("records %q, want a,b and 1,2", got)
+	}
+}
@@ -0,0 +1,166 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Metadata of the package document shown to readers
+	opfRoles = markupRoles{
+		block: func(name string) bool { return name == "dc:title" || name == "dc:description" },
+	}
+	// Labels of the EPUB 2 table of contents
+	ncxRoles = markupRoles{
+		local: true,
+		block: func(name string) bool { return name == "text" },
+	}
+)
+
+// epubCmd represents the epub command
+var epubCmd = &cobra.Command{
+	Use:   "epub",
+	Short: "Translate EPUB e-books",
+	Long: `Translates EPUB 2 and EPUB 3 e-books without external tools. Every XHTML
+document of the manifest (chapters and the navigation document), the labels of the
+NCX table of contents and the title and description of the book are translated.
+Inline markup such as emphasis, links and footnote references keeps its place,
+and code, preformatted text, scripts and styles are left alone.
+
+The dc:language of the package and the xml:lang/lang attributes of the documents
+are set to the target language. The book is repackaged with the uncompressed
+mimetype entry first, as EPUB readers require.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateEPUB)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(epubCmd)
+}
+
+// **************************************************************************
+// translateEPUB is the fileHandler of the epub command. The package document
+// is located through META-INF/container.xml and its manifest lists the
+// documents to translate; all of them are translated in one batch.
+// --------------------------------------------------------------------------
+func translateEPUB(name string, data []byte) ([]byte, error) {
+	opfPath, err := epubPackagePath(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := readZipEntries(data, func(name string) bool { return name == opfPath })
+	if err != nil {
+		return nil, err
+	}
+	opf, err := parseMarkup(entries[opfPath], opfRoles)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", opfPath, err)
+	}
+
+	// Collect the documents listed in the manifest
+	roles := make(map[string]markupRoles)
+	for _, t := range opf.toks {
+		el, ok := t.tok.(xml.StartElement)
+		if !ok || el.Name.Local != "item" {
+			continue
+		}
+		href, _ := xmlAttr(el, "href")
+		mediaType, _ := xmlAttr(el, "media-type")
+		if u, err := url.PathUnescape(href); err == nil {
+			href = u
+		}
+		switch mediaType {
+		case "application/xhtml+xml":
+			roles[path.Join(path.Dir(opfPath), href)] = xhtmlRoles
+		case "application/x-dtbncx+xml":
+			roles[path.Join(path.Dir(opfPath), href)] = ncxRoles
+		}
+	}
+
+	entries, err = readZipEntries(data, func(name string) bool {
+		_, ok := roles[name]
+		return ok
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Process documents in a stable order
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	docs := []*markupDoc{opf}
+	for _, name := range names {
+		doc, err := parseMarkup(entries[name], roles[name])
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if err := translateRichParagraphs(richParagraphs(docs...)); err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[string][]byte, len(docs))
+	replacements[opfPath] = opf.rewrite(append(opf.rootLangEdit(targetLang), opf.elementTextEdits("dc:language", targetLang)...)...)
+	for i, name := range names {
+		doc := docs[i+1]
+		replacements[name] = doc.rewrite(doc.rootLangEdit(targetLang)...)
+	}
+
+	return writeZip(data, replacements)
+}
+
+// epubPackagePath returns the path of the package document (.opf) named by
+// META-INF/container.xml
+func epubPackagePath(data []byte) (string, error) {
+	const container = "META-INF/container.xml"
+	entries, err := readZipEntries(data, func(name string) bool { return name == container })
+	if err != nil {
+		return "", err
+	}
+	content, ok := entries[container]
+	if !ok {
+		return "", fmt.Errorf("no %v found", container)
+	}
+
+	toks, err := scanXML(content)
+	if err != nil {
+		return "", fmt.Errorf("%v: %v", container, err)
+	}
+	for _, t := range toks {
+		if el, ok := t.tok.(xml.StartElement); ok && el.Name.Local == "rootfile" {
+			if fullPath, ok := xmlAttr(el, "full-path"); ok {
+				return fullPath, nil
+			}
+		}
+	}
+
+	return "", fmt.Errorf("no package document named in %v", container)
+}
@@ -0,0 +1,373 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Code spans, doc links and URLs of comments
+	reGoCommentPlaceholder = regexp.MustCompile("`[^`]*`|\\[[^\\[\\]\\s]+\\]|https?://\\S+")
+	// Template actions and printf verbs of string literals
+	reGoStringPlaceholder = regexp.MustCompile(`(?s)\{\{.*?\}\}|` + printfVerbPattern)
+
+	// Compiler directives (//go:generate, //nolint, //export) and build tags
+	reGoDirective = regexp.MustCompile(`^//(?:[a-z0-9]+:|line |export |extern |\s*\+build)`)
+	// List items of doc comments
+	reGoListItem = regexp.MustCompile(`^(\s+(?:[-*+•]|\d+[.)])\s+)(.*)$`)
+	// Decoration of lines in /* */ comments
+	reGoBlockLine = regexp.MustCompile(`^(\s*(?:\*\s?)?)(.*)$`)
+)
+
+// gosourceCmd represents the gosource command
+var gosourceCmd = &cobra.Command{
+	Use:   "gosource",
+	Short: "Translate comments and string literals of Go source files",
+	Long: `Translates the comments of a Go source file and, optionally, the string
+literals passed to selected functions. The file is parsed with go/parser, only
+the comments and literals are replaced, and the result is formatted with
+go/format, so it still compiles.
+
+Comments are translated paragraph by paragraph and re-wrapped to the width of
+the original lines. Code blocks, directives such as //go:generate, build
+constraints, license headers and cgo preambles are left alone, and so are code
+spans, doc links, URLs and the names declared in the file.
+
+String literals are translated when they are arguments of a call whose function
+matches a --strings pattern, e.g. errors.New, fmt.Errorf or '*.Printf'; printf
+verbs and template actions are kept.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateGoSource)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gosourceCmd)
+
+	gosourceCmd.Flags().BoolVarP(&goComments, "comments", "", true, "Translate comments")
+	gosourceCmd.Flags().StringArrayVarP(&goStrings, "strings", "", []string{}, "Functions whose string literal arguments are translated (can be specified multiple times)")
+}
+
+// goCommentLine is a line of a comment split into its decoration and text
+type goCommentLine struct {
+	prefix string
+	text   string
+	item   bool // Whether the line starts a list item
+	para   int  // Index of the paragraph starting at the line, -1 if kept as is, -2 for following lines
+}
+
+// goComment is a comment to rewrite: a group of // comments or a /* */ one
+type goComment struct {
+	start int
+	end   int
+	block bool
+	lines []goCommentLine
+}
+
+// **************************************************************************
+// translateGoSource is the fileHandler of the gosource command.
+// --------------------------------------------------------------------------
+func translateGoSource(name string, data []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, data, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Go source: %v", err)
+	}
+	offset := func(pos token.Pos) int { return fset.Position(pos).Offset }
+
+	var edits []xmlEdit
+	if goComments {
+		comments := collectGoComments(file, data, offset)
+		var segs []segment
+		for _, c := range comments {
+			for k := range c.lines {
+				if c.lines[k].para >= 0 {
+					c.lines[k].para = len(segs)
+					segs = append(segs, segment{Text: goParagraph(c.lines, k)})
+				}
+			}
+		}
+
+		strOut, err := translateSegments(segs, goCommentProtector(file))
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range comments {
+			edits = append(edits, xmlEdit{start: c.start, end: c.end, text: c.render(strOut, data)})
+		}
+	}
+
+	if len(goStrings) > 0 {
+		lits := collectGoStrings(file, goStrings)
+		segs := make([]segment, len(lits))
+		for k, lit := range lits {
+			value, _ := strconv.Unquote(lit.Value)
+			segs[k] = segment{Text: value}
+		}
+
+		strOut, err := translateSegments(segs, regexpProtector(reGoStringPlaceholder))
+		if err != nil {
+			return nil, err
+		}
+		for k, lit := range lits {
+			text := strconv.Quote(strOut[k])
+			if strings.HasPrefix(lit.Value, "`") && !strings.Contains(strOut[k], "`") {
+				text = "`" + strOut[k] + "`"
+			}
+			edits = append(edits, xmlEdit{start: offset(lit.Pos()), end: offset(lit.End()), text: text})
+		}
+	}
+
+	out, err := format.Source(applyXMLEdits(data, edits))
+	if err != nil {
+		return nil, fmt.Errorf("translated source is not valid Go: %v", err)
+	}
+
+	return out, nil
+}
+
+// **************************************************************************
+// collectGoComments returns the comments to translate. Comments above the
+// package clause other than the package documentation (license headers,
+// build constraints) and the cgo preamble are skipped.
+// --------------------------------------------------------------------------
+func collectGoComments(file *ast.File, data []byte, offset func(token.Pos) int) []*goComment {
+	skip := make(map[*ast.CommentGroup]bool)
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			for _, spec := range gen.Specs {
+				if spec.(*ast.ImportSpec).Path.Value == `"C"` {
+					skip[gen.Doc] = true
+				}
+			}
+		}
+	}
+
+	var comments []*goComment
+	for _, group := range file.Comments {
+		if skip[group] || group.End() < file.Package && group != file.Doc {
+			continue
+		}
+
+		var lines *goComment
+		for _, c := range group.List {
+			if strings.HasPrefix(c.Text, "/*") {
+				comments = append(comments, newGoBlockComment(c, offset))
+				lines = nil
+				continue
+			}
+			if lines == nil {
+				lines = &goComment{start: offset(c.Pos())}
+				comments = append(comments, lines)
+			}
+			lines.end = offset(c.End())
+			lines.lines = append(lines.lines, goLineComment(c.Text))
+		}
+	}
+
+	for _, c := range comments {
+		c.paragraphs()
+	}
+
+	return comments
+}
+
+// goLineComment splits a // comment. Indented lines are list items or code;
+// gofmt indents both with a tab, e.g. "//\t  - item" and "//\tcode".
+func goLineComment(text string) goCommentLine {
+	body := strings.TrimPrefix(text, "//")
+	lead := strings.TrimPrefix(body, " ")
+	switch {
+	case reGoDirective.MatchString(text) || body != "" && !strings.HasPrefix(body, " ") && !strings.HasPrefix(body, "\t"):
+		return goCommentLine{prefix: text, para: -1}
+	case strings.HasPrefix(lead, " ") || strings.HasPrefix(lead, "\t"):
+		if m := reGoListItem.FindStringSubmatch(lead); m != nil {
+			return goCommentLine{prefix: text[:len(text)-len(lead)] + m[1], text: m[2], item: true}
+		}
+		return goCommentLine{prefix: text, para: -1} // Code block
+	}
+
+	return goCommentLine{prefix: "// ", text: strings.TrimPrefix(body, " ")}
+}
+
+// newGoBlockComment splits a /* */ comment into lines
+func newGoBlockComment(c *ast.Comment, offset func(token.Pos) int) *goComment {
+	comment := &goComment{start: offset(c.Pos()), end: offset(c.End()), block: true}
+	body := strings.TrimSuffix(strings.TrimPrefix(c.Text, "/*"), "*/")
+	for _, line := range strings.Split(body, "\n") {
+		m := reGoBlockLine.FindStringSubmatch(line)
+		switch {
+		case strings.TrimSpace(m[2]) == "":
+			comment.lines = append(comment.lines, goCommentLine{prefix: line, para: -1})
+		case strings.HasPrefix(m[2], "\t") || strings.HasPrefix(m[2], "  "):
+			comment.lines = append(comment.lines, goCommentLine{prefix: line, para: -1})
+		default:
+			comment.lines = append(comment.lines, goCommentLine{prefix: m[1], text: m[2]})
+		}
+	}
+
+	return comment
+}
+
+// paragraphs marks the lines starting a paragraph; a paragraph continues over
+// the following lines with the same decoration
+func (c *goComment) paragraphs() {
+	for k := range c.lines {
+		line := &c.lines[k]
+		if line.para == -1 {
+			continue
+		}
+		if line.text == "" {
+			line.para = -1
+			continue
+		}
+		prev := k - 1
+		if prev >= 0 && c.lines[prev].para != -1 && c.lines[prev].prefix == line.prefix && !line.item {
+			line.para = -2
+		}
+	}
+}
+
+// goParagraph returns the text of the paragraph starting at line k
+func goParagraph(lines []goCommentLine, k int) string {
+	words := []string{lines[k].text}
+	for k++; k < len(lines) && lines[k].para == -2; k++ {
+		words = append(words, lines[k].text)
+	}
+
+	return strings.Join(words, " ")
+}
+
+// render returns the comment with the translated paragraphs. Paragraphs of
+// several lines are wrapped to the width of their longest line.
+func (c *goComment) render(strOut []string, data []byte) string {
+	// Indentation of the lines following the first one
+	lineStart := bytes.LastIndexByte(data[:c.start], '\n') + 1
+	indent := string(data[lineStart:c.start])
+	if strings.TrimSpace(indent) != "" {
+		indent = ""
+	}
+
+	var out []string
+	for k := 0; k < len(c.lines); k++ {
+		line := c.lines[k]
+		if line.para < 0 {
+			out = append(out, line.prefix)
+			continue
+		}
+
+		width, n := utf8.RuneCountInString(line.text), 1
+		for ; k+n < len(c.lines) && c.lines[k+n].para == -2; n++ {
+			width = max(width, utf8.RuneCountInString(c.lines[k+n].text))
+		}
+		text := strOut[line.para]
+		wrapped := []string{text}
+		if n > 1 {
+			wrapped = wrapWords(strings.Fields(text), width, nil)
+		}
+		for _, w := range wrapped {
+			out = append(out, line.prefix+w)
+		}
+		k += n - 1
+	}
+
+	if c.block {
+		return "/*" + strings.Join(out, "\n") + "*/"
+	}
+	for k := range out {
+		out[k] = strings.TrimRight(out[k], " ")
+	}
+
+	return strings.Join(out, "\n"+indent)
+}
+
+// goCommentProtector keeps code spans, doc links, URLs and the top-level
+// names declared in the file out of the translation
+func goCommentProtector(file *ast.File) protector {
+	var names []string
+	add := func(ident *ast.Ident) {
+		if len(ident.Name) > 1 && ident.Name != "_" {
+			names = append(names, regexp.QuoteMeta(ident.Name))
+		}
+	}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			add(decl.Name)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec.Name)
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						add(name)
+					}
+				}
+			}
+		}
+	}
+	if len(names) == 0 {
+		return regexpProtector(reGoCommentPlaceholder)
+	}
+
+	// Longer names first, so that they win over their prefixes
+	sort.Slice(names, func(i, j int) bool { return len(names[i]) > len(names[j]) })
+
+	return regexpProtector(regexp.MustCompile(reGoCommentPlaceholder.String() + `|\b(?:` + strings.Join(names, "|") + `)\b`))
+}
+
+// collectGoStrings returns the string literals passed to calls of functions
+// matching any of the patterns
+func collectGoStrings(file *ast.File, patterns []string) []*ast.BasicLit {
+	var lits []*ast.BasicLit
+	ast.Inspect(file, func(n ast.Node) bool {
+		call, ok := n.(*ast.CallExpr)
+		if !ok {
+			return true
+		}
+		name := types.ExprString(call.Fun)
+		for _, pattern := range patterns {
+			if ok, _ := path.Match(pattern, name); !ok {
+				continue
+			}
+			for _, arg := range call.Args {
+				if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					lits = append(lits, lit)
+				}
+			}
+			break
+		}
+		return true
+	})
+
+	return lits
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateGoSource(t *testing.T) {
+	defer func(comments bool, strs []string) { goComments, goStrings = comments, strs }(goComments, goStrings)
+	goComments, goStrings = true, []string{"errors.New", "*.Printf"}
+	fakeTranslate(t, strings.ToUpper)
+
+	src := `// Copyright 2025 Example.
+
+// Package demo parses things.
+package demo
+
+import (
+	"errors"
+	"fmt"
+)
+
+//go:generate stringer -type=Kind
+
+// Parse reads the input and returns a value; see ` + "`Parse`" + ` and
+// https://example.com/x for the details of the format.
+//
+//		code block stays
+//
+//	  - first item
+//	  - second item
+func Parse() error {
+	fmt.Printf("%d items\n", 3) /* block note */
+	fmt.Println("not selected")
+	return errors.New(` + "`bad input`" + `)
+}
+`
+	want := `// Copyright 2025 Example.
+
+// PACKAGE DEMO PARSES THINGS.
+package demo
+
+import (
+	"errors"
+	"fmt"
+)
+
+//go:generate stringer -type=Kind
+
+// Parse READS THE INPUT AND RETURNS A VALUE; SEE ` + "`Parse`" + ` AND
+// https://example.com/x FOR THE DETAILS OF THE FORMAT.
+//
+//		code block stays
+//
+//	  - FIRST ITEM
+//	  - SECOND ITEM
+func Parse() error {
+	fmt.Printf("%d ITEMS\n", 3) /* BLOCK NOTE */
+	fmt.Println("not selected")
+	return errors.New(` + "`BAD INPUT`" + `)
+}
+`
+	got, err := translateGoSource("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("translateGoSource() = %s, want %s", got, want)
+	}
+}
+
+func TestTranslateGoSourceRewrap(t *testing.T) {
+	defer func(comments bool, strs []string) { goComments, goStrings = comments, strs }(goComments, goStrings)
+	goComments, goStrings = true, nil
+	fakeTranslate(t, func(s string) string { return strings.ReplaceAll(s, "word", "longer word") })
+
+	src := "package demo\n\n// One word two word three word four\n// word five.\nvar x = 1\n"
+	want := "package demo\n\n// One longer word two longer word\n// three longer word four longer\n// word five.\nvar x = 1\n"
+	got, err := translateGoSource("demo.go", []byte(src))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("translateGoSource() = %q, want %q", got, want)
+	}
+
+	// A translation breaking the syntax is refused rather than written
+	fakeTranslate(t, func(s string) string { return s + " */ }" })
+	if _, err := translateGoSource("demo.go", []byte("package demo\n\n/* Note. */\nvar x = 1\n")); err == nil {
+		t.Errorf("translateGoSource() accepted a translation breaking the source")
+	}
+}
+
+func TestGoLineComment(t *testing.T) {
+	tests := []struct {
+		text string
+		want goCommentLine
+	}{
+		{"// Plain text", goCommentLine{prefix: "// ", text: "Plain text"}},
+		{"//", goCommentLine{prefix: "// "}},
+		{"//go:generate stringer", goCommentLine{prefix: "//go:generate stringer", para: -1}},
+		{"//nolint:errcheck", goCommentLine{prefix: "//nolint:errcheck", para: -1}},
+		{"//\tx := 1", goCommentLine{prefix: "//\tx := 1", para: -1}},
+		{"//   x := 1", goCommentLine{prefix: "//   x := 1", para: -1}},
+		{"//\t  - gofmt item", goCommentLine{prefix: "//\t  - ", text: "gofmt item", item: true}},
+		{"//\t 1. numbered", goCommentLine{prefix: "//\t 1. ", text: "numbered", item: true}},
+		{"//   - spaced item", goCommentLine{prefix: "//   - ", text: "spaced item", item: true}},
+	}
+
+	for _, tt := range tests {
+		if got := goLineComment(tt.text); got != tt.want {
+			t.Errorf("goLineComment(%q) = %+v, want %+v", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,315 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+// Largest number tried when looking for a sample of a plural form
+const maxPluralSample = 1000
+
+var (
+	// Named placeholders of gotext messages ({City}) and printf verbs
+	reGotextPlaceholder = regexp.MustCompile(`\{[^{}\s]+\}|` + printfVerbPattern)
+
+	// Names of plural forms as used by the cases of plural selects
+	pluralFormNames = map[plural.Form]string{
+		plural.Other: "other", plural.Zero: "zero", plural.One: "one",
+		plural.Two: "two", plural.Few: "few", plural.Many: "many",
+	}
+)
+
+// gotextCmd represents the gotext command
+var gotextCmd = &cobra.Command{
+	Use:   "gotext",
+	Short: "Translate golang.org/x/text message catalogs (gotext JSON)",
+	Long: `Translates the message catalogs extracted by the gotext tool, such as
+locales/en/out.gotext.json, into a messages.gotext.json for the target language
+that golang.org/x/text/message can load. When --output is an existing directory
+the catalog is written to <output>/<target>/messages.gotext.json.
+
+Placeholders like {City} and printf verbs are kept. Plural selects are rebuilt
+for the plural forms of the target language (e.g. one, few, many and other for
+Ukrainian); each form is translated from the English case matching a sample
+number of that form, so the noun gets the right grammatical form.
+
+Translations in an existing output catalog that are not marked as fuzzy are
+kept; all machine translations are marked as fuzzy.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isDir(outputFile) {
+			outputFile = filepath.Join(outputFile, targetLang, "messages.gotext.json")
+		}
+
+		return runFileHandler(translateGotext)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(gotextCmd)
+}
+
+// gotextJob is a string to translate and the function storing its translation
+type gotextJob struct {
+	seg segment
+	set func(text string)
+}
+
+// **************************************************************************
+// translateGotext is the fileHandler of the gotext command. The catalog is
+// copied with the language set to the target, and every message gets a
+// translation: the one of the existing output catalog, or a new machine
+// translation marked as fuzzy.
+// --------------------------------------------------------------------------
+func translateGotext(name string, data []byte) ([]byte, error) {
+	root, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+	messages := root.get("messages")
+	if root.kind != jsonObject || messages == nil || messages.kind != jsonArray {
+		return nil, fmt.Errorf("not a gotext catalog: no messages array")
+	}
+
+	srcLang := sourceLang
+	if srcLang == "auto" {
+		srcLang = "en"
+		if lang := root.get("language"); lang != nil && lang.kind == jsonString {
+			srcLang = lang.str
+		}
+	}
+	srcTag, err := language.Parse(srcLang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source language %q: %v", srcLang, err)
+	}
+	dstTag, err := language.Parse(targetLang)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target language %q: %v", targetLang, err)
+	}
+
+	existing, err := existingGotextTranslations(outputFile)
+	if err != nil {
+		return nil, err
+	}
+
+	out := root.clone()
+	out.set("language", &jsonNode{kind: jsonString, str: targetLang}, "")
+	outMessages := out.get("messages")
+
+	var jobs []gotextJob
+	for _, msg := range outMessages.values {
+		if msg.kind != jsonObject {
+			continue
+		}
+		id := msg.get("id")
+		if id == nil {
+			continue
+		}
+		key := string(id.encode(jsonStyle{}))
+		if translation, ok := existing[key]; ok {
+			msg.set("translation", translation, "message")
+			continue
+		}
+
+		// Plural selects are only found in translations; plain messages may
+		// have their text in either field
+		translation := &jsonNode{kind: jsonString}
+		source := msg.get("translation")
+		if source != nil && source.get("select") != nil {
+			translation = source.clone()
+			jobs = gotextSelectJobs(translation.get("select"), srcTag, dstTag, jobs)
+		} else {
+			if message := msg.get("message"); message != nil && message.kind == jsonString && message.str != "" {
+				source = message
+			}
+			if source != nil && source.kind == jsonString {
+				jobs = append(jobs, gotextJob{seg: segment{Text: source.str}, set: func(text string) { translation.str = text }})
+			}
+		}
+		msg.set("translation", translation, "message")
+		msg.set("fuzzy", &jsonNode{kind: jsonLiteral, raw: "true"}, "")
+	}
+
+	segs := make([]segment, len(jobs))
+	for i, job := range jobs {
+		segs[i] = job.seg
+	}
+	strOut, err := translateSegments(segs, regexpProtector(reGotextPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, job := range jobs {
+		job.set(strOut[i])
+	}
+
+	return out.encode(detectJSONStyle(data)), nil
+}
+
+// **************************************************************************
+// gotextSelectJobs rebuilds the cases of a select for the target language.
+// Exact cases like "=0" are translated as they are. For every plural form
+// of the target language a sample number is substituted for the argument,
+// e.g. "{N} files" becomes "3 files", and the number in the translation is
+// turned back into the placeholder. Forms without an integer sample, and
+// translations that lose the number, use the case translated with the
+// placeholder kept.
+// --------------------------------------------------------------------------
+func gotextSelectJobs(sel *jsonNode, srcTag, dstTag language.Tag, jobs []gotextJob) []gotextJob {
+	cases := sel.get("cases")
+	if cases == nil || cases.kind != jsonObject {
+		return jobs
+	}
+
+	// Text of a case, which is either a string or an object with a msg
+	caseText := func(value *jsonNode) *jsonNode {
+		if value.kind == jsonObject {
+			value = value.get("msg")
+		}
+		if value == nil || value.kind != jsonString {
+			return nil
+		}
+		return value
+	}
+
+	feature := sel.get("feature")
+	arg := sel.get("arg")
+	if feature == nil || feature.str != "plural" || arg == nil || cases.get("other") == nil {
+		for _, value := range cases.values {
+			if text := caseText(value); text != nil {
+				jobs = append(jobs, gotextJob{seg: segment{Text: text.str}, set: func(s string) { text.str = s }})
+			}
+		}
+		return jobs
+	}
+
+	placeholder := "{" + arg.str + "}"
+	source := cases.clone()
+	other := source.get("other")
+	out := &jsonNode{kind: jsonObject}
+	for i, key := range source.keys {
+		if strings.HasPrefix(key, "=") || strings.HasPrefix(key, "<") {
+			value := source.values[i]
+			out.set(key, value, "")
+			if text := caseText(value); text != nil {
+				jobs = append(jobs, gotextJob{seg: segment{Text: text.str}, set: func(s string) { text.str = s }})
+			}
+		}
+	}
+
+	samples := pluralSamples(dstTag)
+	for _, form := range []plural.Form{plural.Zero, plural.One, plural.Two, plural.Few, plural.Many, plural.Other} {
+		n, ok := samples[form]
+		if !ok && form != plural.Other {
+			continue
+		}
+
+		value := other
+		if ok {
+			srcForm := plural.Cardinal.MatchPlural(srcTag, n, 0, 0, 0, 0)
+			if v := source.get(pluralFormNames[srcForm]); v != nil {
+				value = v
+			}
+		}
+		value = value.clone()
+		text := caseText(value)
+		if text == nil {
+			continue
+		}
+		out.set(pluralFormNames[form], value, "")
+
+		withPlaceholder := text.str
+		fallback := ""
+		jobs = append(jobs, gotextJob{seg: segment{Text: withPlaceholder}, set: func(s string) {
+			fallback = s
+			text.str = s
+		}})
+		if !ok || !strings.Contains(withPlaceholder, placeholder) {
+			continue
+		}
+
+		number := strconv.Itoa(n)
+		reNumber := regexp.MustCompile(`\b` + number + `\b`)
+		jobs = append(jobs, gotextJob{seg: segment{Text: strings.ReplaceAll(withPlaceholder, placeholder, number)}, set: func(s string) {
+			if loc := reNumber.FindStringIndex(s); loc != nil {
+				text.str = s[:loc[0]] + placeholder + s[loc[1]:]
+			} else {
+				text.str = fallback
+			}
+		}})
+	}
+	cases.keys, cases.values = out.keys, out.values
+
+	return jobs
+}
+
+// pluralSamples returns an integer sample for every plural form of the
+// language that integers can take. 1 is tried first, then larger numbers and
+// 0 last, so that "many" and "other" get samples like 5 rather than 0.
+func pluralSamples(tag language.Tag) map[plural.Form]int {
+	samples := make(map[plural.Form]int)
+	for n := 1; n <= maxPluralSample+1; n++ {
+		sample := n % (maxPluralSample + 1)
+		form := plural.Cardinal.MatchPlural(tag, sample, 0, 0, 0, 0)
+		if _, ok := samples[form]; !ok {
+			samples[form] = sample
+		}
+	}
+
+	return samples
+}
+
+// existingGotextTranslations returns the translations of a catalog that are
+// not marked as fuzzy, keyed by the encoded message id
+func existingGotextTranslations(path string) (map[string]*jsonNode, error) {
+	translations := make(map[string]*jsonNode)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return translations, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %v", path, err)
+	}
+
+	root, err := parseJSONTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", path, err)
+	}
+	messages := root.get("messages")
+	if messages == nil {
+		return translations, nil
+	}
+	for _, msg := range messages.values {
+		id, translation := msg.get("id"), msg.get("translation")
+		if id == nil || translation == nil || translation.kind == jsonString && translation.str == "" {
+			continue
+		}
+		if fuzzy := msg.get("fuzzy"); fuzzy != nil && fuzzy.raw == "true" {
+			continue
+		}
+		translations[string(id.encode(jsonStyle{}))] = translation
+	}
+
+	return translations, nil
+}
@@ -0,0 +1,146 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"golang.org/x/text/feature/plural"
+	"golang.org/x/text/language"
+)
+
+func TestPluralSamples(t *testing.T) {
+	tests := []struct {
+		lang string
+		want map[plural.Form]int
+	}{
+		{"en", map[plural.Form]int{plural.One: 1, plural.Other: 2}},
+		{"uk", map[plural.Form]int{plural.One: 1, plural.Few: 2, plural.Many: 5}},
+		{"ja", map[plural.Form]int{plural.Other: 1}},
+	}
+
+	for _, tt := range tests {
+		got := pluralSamples(language.MustParse(tt.lang))
+		if len(got) != len(tt.want) {
+			t.Errorf("pluralSamples(%v) = %v, want %v", tt.lang, got, tt.want)
+			continue
+		}
+		for form, n := range tt.want {
+			if got[form] != n {
+				t.Errorf("pluralSamples(%v) = %v, want %v", tt.lang, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestTranslateGotext(t *testing.T) {
+	defer func(src, dst, out string) { sourceLang, targetLang, outputFile = src, dst, out }(sourceLang, targetLang, outputFile)
+	sourceLang, targetLang = "auto", "uk"
+	outputFile = filepath.Join(t.TempDir(), "messages.gotext.json")
+
+	// Samples get the noun form matching their number; cases sent with the
+	// placeholder get the one for 5, which shows when a form falls back
+	dict := strings.NewReplacer("Hello", "Привіт,", "No files", "Немає файлів",
+		"1 file", "1 файл", "2 files", "2 файли", "5 files", "5 файлів", " files", " файлів", " file", " файлів")
+	sent := fakeTranslate(t, dict.Replace)
+
+	data := `{
+    "language": "en",
+    "messages": [
+        {
+            "id": "Hello {City}",
+            "message": "Hello {City}",
+            "translation": ""
+        },
+        {
+            "id": "{N} files",
+            "message": "{N} files",
+            "translation": {
+                "select": {
+                    "feature": "plural",
+                    "arg": "N",
+                    "cases": {
+                        "=0": "No files",
+                        "one": "{N} file",
+                        "other": "{N} files"
+                    }
+                }
+            }
+        }
+    ]
+}
+`
+	got, err := translateGotext("out.gotext.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{
+    "language": "uk",
+    "messages": [
+        {
+            "id": "Hello {City}",
+            "message": "Hello {City}",
+            "translation": "Привіт, {City}",
+            "fuzzy": true
+        },
+        {
+            "id": "{N} files",
+            "message": "{N} files",
+            "translation": {
+                "select": {
+                    "feature": "plural",
+                    "arg": "N",
+                    "cases": {
+                        "=0": "Немає файлів",
+                        "one": "{N} файл",
+                        "few": "{N} файли",
+                        "many": "{N} файлів",
+                        "other": "{N} файлів"
+                    }
+                }
+            },
+            "fuzzy": true
+        }
+    ]
+}
+`
+	if string(got) != want {
+		t.Errorf("translateGotext() = %s, want %s", got, want)
+	}
+	for _, s := range []string{"1 file", "2 files", "5 files"} {
+		if !slices.Contains(*sent, s) {
+			t.Errorf("sent %q, want the sample %q", *sent, s)
+		}
+	}
+}
+
+func TestTranslateGotextKeepsExisting(t *testing.T) {
+	defer func(src, dst, out string) { sourceLang, targetLang, outputFile = src, dst, out }(sourceLang, targetLang, outputFile)
+	sourceLang, targetLang = "en", "de"
+	outputFile = filepath.Join(t.TempDir(), "messages.gotext.json")
+	sent := fakeTranslate(t, strings.ToUpper)
+
+	existing := `{"language": "de", "messages": [` +
+		`{"id": "Open", "translation": "Öffnen"}, {"id": "Close", "translation": "Schliessen", "fuzzy": true}]}`
+	if err := os.WriteFile(outputFile, []byte(existing), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	data := `{"language": "en", "messages": [{"id": "Open", "message": "Open"}, {"id": "Close", "message": "Close"}]}`
+	got, err := translateGotext("out.gotext.json", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"language":"de","messages":[{"id":"Open","message":"Open","translation":"Öffnen"},` +
+		`{"id":"Close","message":"Close","translation":"CLOSE","fuzzy":true}]}`
+	if string(got) != want {
+		t.Errorf("translateGotext() = %s, want %s", got, want)
+	}
+	if len(*sent) != 1 || (*sent)[0] != "Close" {
+		t.Errorf("sent %q, want only the fuzzy message", *sent)
+	}
+}
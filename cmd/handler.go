@@ -0,0 +1,52 @@
+/*
+This file contains the glue shared by the commands that translate structured
+files (resource bundles, documents, subtitles, ...). Each such command boils
+down to a fileHandler that turns the content of the input file into the
+translated content of the output file.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// fileHandler translates the content of a file. The name is used by handlers
+// that support several dialects to pick the right one.
+type fileHandler func(name string, data []byte) ([]byte, error)
+
+// **************************************************************************
+// runFileHandler reads the input file, translates it with handler and writes
+// the result to the output file, creating the output directory if needed.
+// It implements the RunE body of every structured file command.
+// --------------------------------------------------------------------------
+func runFileHandler(handler fileHandler) error {
+	if inputFile == outputFile {
+		return fmt.Errorf("input file and output file are the same: %v", inputFile)
+	}
+
+	// Start indicator:
+	shutdownCh := make(chan struct{})
+	go indicator(shutdownCh)
+
+	defer close(shutdownCh) // Signal indicator() to terminate
+
+	strInp, err := readInp(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+
+	// Handlers see the name without .gz or .bz2, as they see the content
+	data, err := handler(uncompressedName(inputFile), []byte(strInp))
+	if err != nil {
+		return fmt.Errorf("failed to translate %v: %v", inputFile, err)
+	}
+
+	// Ensure the output directory exists
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	return writeOut(outputFile, []string{string(data)})
+}
@@ -0,0 +1,424 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Supported JSON resource formats
+const (
+	i18nAuto    = "auto"
+	i18nI18next = "i18next"
+	i18nGoI18n  = "go-i18n"
+	i18nARB     = "arb"
+)
+
+var (
+	// {{name}}, {{- html}}, $t(nested.key), <1>…</1> and <br/> of react-i18next
+	reI18nextPlaceholder = regexp.MustCompile(`\{\{[^{}]*\}\}|\$t\([^()]*\)|</?\d+\s*/?>|<br\s*/?>`)
+
+	// Reserved keys of a go-i18n message object
+	goI18nPluralKeys = []string{"zero", "one", "two", "few", "many", "other"}
+	goI18nKeys       = append([]string{"id", "description", "hash", "leftdelim", "rightdelim", "translation"}, goI18nPluralKeys...)
+)
+
+// i18nCmd represents the i18n command
+var i18nCmd = &cobra.Command{
+	Use:   "i18n",
+	Short: "Translate JSON i18n resource files (i18next, go-i18n, ARB)",
+	Long: `Translates the string values of JSON resource files while keeping keys, key order
+and formatting of the document. Interpolation placeholders such as {{name}} or {count}
+are never sent for translation.
+
+Supported formats:
+  i18next  nested JSON of react-i18next/i18next
+  go-i18n  message files of github.com/nicksnyder/go-i18n
+  arb      Flutter Application Resource Bundle; @-metadata is kept and the
+           description of a message is used as its context
+
+With --format auto, .arb files are read as ARB, active.*.json and translate.*.json
+as go-i18n and anything else as i18next.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateI18n)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(i18nCmd)
+
+	i18nCmd.Flags().StringVarP(&i18nFormat, "format", "f", i18nAuto, "Resource format: auto, i18next, go-i18n or arb")
+}
+
+// translateI18n is the fileHandler of the i18n command
+func translateI18n(name string, data []byte) ([]byte, error) {
+	tree, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	format := i18nFormat
+	if format == i18nAuto {
+		format = detectI18nFormat(name)
+	}
+
+	var nodes []*jsonNode
+	var segs []segment
+	var protect protector
+	switch format {
+	case i18nI18next:
+		nodes, segs = collectJSONStrings(tree, nil, nil)
+		protect = regexpProtector(reI18nextPlaceholder)
+	case i18nGoI18n:
+		c := &goI18nCollector{delims: map[[2]string]bool{{"{{", "}}"}: true}}
+		c.collect(tree)
+		nodes, segs, protect = c.nodes, c.segs, c.protector()
+	case i18nARB:
+		nodes, segs, err = collectARB(tree)
+		if err != nil {
+			return nil, err
+		}
+		protect = icuProtector
+	default:
+		return nil, fmt.Errorf("unknown i18n format: %v", format)
+	}
+
+	strOut, err := translateSegments(segs, protect)
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		node.str = strOut[i]
+	}
+
+	// No key is added, so the translations are written in place
+	return tree.splice(data), nil
+}
+
+func detectI18nFormat(name string) string {
+	base := strings.ToLower(filepath.Base(name))
+	switch {
+	case strings.HasSuffix(base, ".arb"):
+		return i18nARB
+	case strings.HasPrefix(base, "active.") || strings.HasPrefix(base, "translate."):
+		return i18nGoI18n
+	default:
+		return i18nI18next
+	}
+}
+
+// collectJSONStrings appends every string leaf of the tree to nodes and segs
+func collectJSONStrings(node *jsonNode, nodes []*jsonNode, segs []segment) ([]*jsonNode, []segment) {
+	switch node.kind {
+	case jsonString:
+		nodes = append(nodes, node)
+		segs = append(segs, segment{Text: node.str})
+	case jsonObject, jsonArray:
+		for _, value := range node.values {
+			nodes, segs = collectJSONStrings(value, nodes, segs)
+		}
+	}
+
+	return nodes, segs
+}
+
+// goI18nCollector gathers the translatable strings of a go-i18n message file
+// together with the template delimiters used by its messages
+type goI18nCollector struct {
+	nodes  []*jsonNode
+	segs   []segment
+	delims map[[2]string]bool
+}
+
+// **************************************************************************
+// collect walks a go-i18n message file. A message is either a plain string
+// or an object with reserved keys (id, description, one, other, ...); only
+// the plural forms and "translation" of such an object are translated and
+// its description becomes the context. Objects without reserved keys are
+// nested message maps.
+// --------------------------------------------------------------------------
+func (c *goI18nCollector) collect(node *jsonNode) {
+	switch node.kind {
+	case jsonString:
+		c.add(node, "")
+	case jsonArray:
+		for _, value := range node.values {
+			c.collect(value)
+		}
+	case jsonObject:
+		if !isGoI18nMessage(node) {
+			for _, value := range node.values {
+				c.collect(value)
+			}
+			return
+		}
+
+		context := ""
+		left, right := "{{", "}}"
+		for i, key := range node.keys {
+			value := node.values[i]
+			if value.kind != jsonString {
+				continue
+			}
+			switch strings.ToLower(key) {
+			case "description":
+				context = value.str
+			case "leftdelim":
+				left = value.str
+			case "rightdelim":
+				right = value.str
+			}
+		}
+		if left != "" && right != "" {
+			c.delims[[2]string{left, right}] = true
+		}
+
+		for i, key := range node.keys {
+			value := node.values[i]
+			key = strings.ToLower(key)
+			switch {
+			case key == "translation" && value.kind == jsonObject:
+				for _, form := range value.values {
+					if form.kind == jsonString {
+						c.add(form, context)
+					}
+				}
+			case (key == "translation" || isGoI18nPluralKey(key)) && value.kind == jsonString:
+				c.add(value, context)
+			}
+		}
+	}
+}
+
+func (c *goI18nCollector) add(node *jsonNode, context string) {
+	c.nodes = append(c.nodes, node)
+	c.segs = append(c.segs, segment{Text: node.str, Context: context})
+}
+
+// protector keeps the template actions of all collected delimiters untouched
+func (c *goI18nCollector) protector() protector {
+	var patterns []string
+	for delims := range c.delims {
+		patterns = append(patterns, regexp.QuoteMeta(delims[0])+`.*?`+regexp.QuoteMeta(delims[1]))
+	}
+	sort.Strings(patterns)
+
+	return regexpProtector(regexp.MustCompile(`(?s)` + strings.Join(patterns, "|")))
+}
+
+func isGoI18nMessage(node *jsonNode) bool {
+	for _, key := range node.keys {
+		for _, reserved := range goI18nKeys {
+			if strings.ToLower(key) == reserved {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func isGoI18nPluralKey(key string) bool {
+	for _, plural := range goI18nPluralKeys {
+		if key == plural {
+			return true
+		}
+	}
+
+	return false
+}
+
+// **************************************************************************
+// collectARB collects the messages of a Flutter ARB file. Keys starting with
+// "@" hold metadata and are left alone, except for "@@locale" which is set to
+// the target language. The description of "@key" is used as the context of
+// "key".
+// --------------------------------------------------------------------------
+func collectARB(tree *jsonNode) ([]*jsonNode, []segment, error) {
+	if tree.kind != jsonObject {
+		return nil, nil, fmt.Errorf("ARB file must contain a JSON object")
+	}
+
+	var nodes []*jsonNode
+	var segs []segment
+	for i, key := range tree.keys {
+		value := tree.values[i]
+		if key == "@@locale" && value.kind == jsonString {
+			value.str = strings.ReplaceAll(targetLang, "-", "_")
+			continue
+		}
+		if strings.HasPrefix(key, "@") || value.kind != jsonString {
+			continue
+		}
+
+		context := ""
+		if desc := tree.get("@" + key).get("description"); desc != nil && desc.kind == jsonString {
+			context = desc.str
+		}
+		nodes = append(nodes, value)
+		segs = append(segs, segment{Text: value.str, Context: context})
+	}
+
+	return nodes, segs, nil
+}
+
+// **************************************************************************
+// icuProtector splits an ICU MessageFormat string (as used by ARB files)
+// into chunks. Simple arguments like {name} and formatted arguments like
+// {n, number} are protected as a whole. For plural, selectordinal and select
+// arguments only the syntax is protected while the text of every case is
+// translated; "#" inside plural cases is protected as well.
+// --------------------------------------------------------------------------
+func icuProtector(text string) []chunk {
+	p := &icuParser{s: text}
+	p.message(false, false)
+	if p.pos < len(p.s) {
+		// Unbalanced "}": keep the rest as text
+		p.add(p.s[p.pos:], false)
+	}
+
+	return p.chunks
+}
+
+type icuParser struct {
+	s      string
+	pos    int
+	chunks []chunk
+}
+
+// add appends text to the chunks, merging it with the last chunk of the same kind
+func (p *icuParser) add(text string, keep bool) {
+	if text == "" {
+		return
+	}
+	if n := len(p.chunks); n > 0 && p.chunks[n-1].keep == keep {
+		p.chunks[n-1].text += text
+		return
+	}
+	p.chunks = append(p.chunks, chunk{text: text, keep: keep})
+}
+
+// message parses message text up to the end of the string or, when nested,
+// up to the "}" closing the enclosing case
+func (p *icuParser) message(inPlural, nested bool) {
+	for p.pos < len(p.s) {
+		switch c := p.s[p.pos]; {
+		case c == '{':
+			if !p.argument(inPlural) {
+				p.add("{", false)
+				p.pos++
+			}
+		case c == '}' && nested:
+			return
+		case c == '#' && inPlural:
+			p.add("#", true)
+			p.pos++
+		default:
+			p.add(p.s[p.pos:p.pos+1], false)
+			p.pos++
+		}
+	}
+}
+
+// argument parses the argument starting at p.pos and reports whether it was
+// well-formed; on failure the parser state is left unchanged
+func (p *icuParser) argument(inPlural bool) bool {
+	start := p.pos
+	saved := append([]chunk(nil), p.chunks...)
+	fail := func() bool {
+		p.pos = start
+		p.chunks = saved
+		return false
+	}
+
+	end := strings.IndexAny(p.s[start+1:], "{},")
+	if end < 0 {
+		return false
+	}
+	end += start + 1
+	switch p.s[end] {
+	case '{':
+		return false
+	case '}':
+		p.add(p.s[start:end+1], true)
+		p.pos = end + 1
+		return true
+	}
+
+	rest := p.s[end+1:]
+	typeEnd := strings.IndexAny(rest, ",}")
+	if typeEnd < 0 {
+		return false
+	}
+	kind := strings.TrimSpace(rest[:typeEnd])
+	if rest[typeEnd] == '}' || (kind != "plural" && kind != "selectordinal" && kind != "select") {
+		// Formatted argument such as {n, number, ::currency/EUR}
+		closing := matchingBrace(p.s, start)
+		if closing < 0 {
+			return false
+		}
+		p.add(p.s[start:closing+1], true)
+		p.pos = closing + 1
+		return true
+	}
+
+	p.pos = end + 1 + typeEnd + 1
+	p.add(p.s[start:p.pos], true)
+	for {
+		// Case selector (e.g. " one", " =0", " other") and its opening brace
+		i := strings.IndexAny(p.s[p.pos:], "{}")
+		if i < 0 {
+			return fail()
+		}
+		i += p.pos
+		p.add(p.s[p.pos:i+1], true)
+		p.pos = i + 1
+		if p.s[i] == '}' {
+			return true
+		}
+
+		p.message(inPlural || kind != "select", true)
+		if p.pos >= len(p.s) {
+			return fail()
+		}
+		p.add("}", true)
+		p.pos++
+	}
+}
+
+// matchingBrace returns the index of the "}" matching the "{" at start, or -1
+func matchingBrace(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import "testing"
+
+func TestTranslateI18nKeepsLayout(t *testing.T) {
+	defer func(lang, format string) { targetLang, i18nFormat = lang, format }(targetLang, i18nFormat)
+	targetLang, i18nFormat = "pt-BR", i18nAuto
+
+	// Values without letters are not sent for translation, so only
+	// @@locale changes and everything else is kept byte for byte
+	data := "{\n    \"@@locale\": \"en\",\n    \"count\": \"{n}\",\n    \"@count\": {\"placeholders\": {\"n\": {}}},\n    \"price\": \"\\u20ac 1\"\n}"
+	want := "{\n    \"@@locale\": \"pt_BR\",\n    \"count\": \"{n}\",\n    \"@count\": {\"placeholders\": {\"n\": {}}},\n    \"price\": \"\\u20ac 1\"\n}"
+
+	got, err := translateI18n("app_en.arb", []byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("translateI18n() = %q, want %q", got, want)
+	}
+}
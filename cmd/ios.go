@@ -0,0 +1,335 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// Format specifiers of Foundation strings, including %#@variable@ references
+// of .stringsdict files
+var reIOSPlaceholder = regexp.MustCompile(`%#@[^@\s]+@|` + printfVerbPattern)
+
+// Keys of a .stringsdict plural rule whose values are translated
+var stringsdictKeys = []string{"NSStringLocalizedFormatKey", "zero", "one", "two", "few", "many", "other"}
+
+// iosCmd represents the ios command
+var iosCmd = &cobra.Command{
+	Use:   "ios",
+	Short: "Translate iOS Localizable.strings and .stringsdict files",
+	Long: `Translates the values of iOS/macOS .strings files and the plural rules of
+.stringsdict files. Keys, format specifiers like %@ or %1$d and %#@var@ references
+are left untouched, and the comment preceding an entry is used as its context.
+UTF-16 .strings files are written back in UTF-16.
+
+When --output is an existing directory the result is written to the
+<lang>.lproj folder for the target language:
+
+  gootrago ios -i en.lproj/Localizable.strings -o . -t uk   # uk.lproj/Localizable.strings`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isDir(outputFile) {
+			outputFile = filepath.Join(outputFile, targetLang+".lproj", filepath.Base(inputFile))
+		}
+
+		return runFileHandler(translateIOS)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(iosCmd)
+}
+
+// translateIOS is the fileHandler of the ios command
+func translateIOS(name string, data []byte) ([]byte, error) {
+	if strings.EqualFold(filepath.Ext(name), ".stringsdict") {
+		return translateStringsdict(data)
+	}
+
+	return translateStrings(data)
+}
+
+// **************************************************************************
+// translateStrings translates a .strings file of "key" = "value"; entries.
+// Only the quoted values are replaced, so comments, keys and layout of the
+// file stay as they are.
+// --------------------------------------------------------------------------
+func translateStrings(data []byte) ([]byte, error) {
+	text, enc, err := decodeUTF16BOM(data)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := parseStrings(text)
+	if err != nil {
+		return nil, err
+	}
+
+	segs := make([]segment, len(entries))
+	for i, e := range entries {
+		segs[i] = segment{Text: e.value, Context: strings.TrimSpace(e.key + ": " + e.comment)}
+	}
+	strOut, err := translateSegments(segs, regexpProtector(reIOSPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]xmlEdit, len(entries))
+	for i, e := range entries {
+		edits[i] = xmlEdit{start: e.start, end: e.end, text: quoteStringsValue(strOut[i])}
+	}
+	out := applyXMLEdits(text, edits)
+
+	if enc != nil {
+		return enc.NewEncoder().Bytes(out)
+	}
+	return out, nil
+}
+
+// stringsEntry is a key/value pair of a .strings file. start and end are the
+// byte range of the quoted value.
+type stringsEntry struct {
+	key     string
+	value   string
+	comment string
+	start   int
+	end     int
+}
+
+// **************************************************************************
+// parseStrings parses the old-style property list syntax of .strings files.
+// Keys may be quoted or bare words; /* */ and // comments are recorded and
+// attached to the entry that follows them.
+// --------------------------------------------------------------------------
+func parseStrings(data []byte) ([]stringsEntry, error) {
+	var entries []stringsEntry
+	comment := ""
+	pos := 0
+	if bytes.HasPrefix(data, []byte("\xef\xbb\xbf")) {
+		pos = 3
+	}
+
+	skipSpace := func() {
+		for pos < len(data) && strings.ContainsRune(" \t\r\n", rune(data[pos])) {
+			pos++
+		}
+	}
+	expect := func(c byte) error {
+		skipSpace()
+		if pos >= len(data) || data[pos] != c {
+			return fmt.Errorf("expected %q at offset %d", c, pos)
+		}
+		pos++
+		return nil
+	}
+
+	for {
+		skipSpace()
+		if pos >= len(data) {
+			return entries, nil
+		}
+
+		switch {
+		case bytes.HasPrefix(data[pos:], []byte("/*")):
+			end := bytes.Index(data[pos+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment at offset %d", pos)
+			}
+			comment = strings.TrimSpace(string(data[pos+2 : pos+2+end]))
+			pos += end + 4
+			continue
+		case bytes.HasPrefix(data[pos:], []byte("//")):
+			end := bytes.IndexByte(data[pos:], '\n')
+			if end < 0 {
+				end = len(data) - pos
+			}
+			comment = strings.TrimSpace(string(data[pos+2 : pos+end]))
+			pos += end
+			continue
+		}
+
+		var e stringsEntry
+		if data[pos] == '"' {
+			key, end, err := unquoteStringsValue(data, pos)
+			if err != nil {
+				return nil, err
+			}
+			e.key, pos = key, end
+		} else {
+			start := pos
+			for pos < len(data) && !strings.ContainsRune(" \t\r\n=;", rune(data[pos])) {
+				pos++
+			}
+			e.key = string(data[start:pos])
+		}
+
+		if err := expect('='); err != nil {
+			return nil, err
+		}
+		skipSpace()
+		if pos >= len(data) || data[pos] != '"' {
+			return nil, fmt.Errorf("expected quoted value for %q at offset %d", e.key, pos)
+		}
+		value, end, err := unquoteStringsValue(data, pos)
+		if err != nil {
+			return nil, err
+		}
+		e.value, e.start, e.end = value, pos, end
+		pos = end
+		if err := expect(';'); err != nil {
+			return nil, err
+		}
+
+		e.comment = comment
+		comment = ""
+		entries = append(entries, e)
+	}
+}
+
+// unquoteStringsValue decodes the quoted string starting at data[start] and
+// returns it together with the offset following the closing quote
+func unquoteStringsValue(data []byte, start int) (string, int, error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(data); i++ {
+		c := data[i]
+		switch c {
+		case '"':
+			return sb.String(), i + 1, nil
+		case '\\':
+			i++
+			if i >= len(data) {
+				break
+			}
+			switch data[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'r':
+				sb.WriteByte('\r')
+			case '0':
+				sb.WriteByte(0)
+			case 'U', 'u':
+				if i+4 < len(data) {
+					if r, err := strconv.ParseUint(string(data[i+1:i+5]), 16, 32); err == nil {
+						sb.WriteRune(rune(r))
+						i += 4
+						continue
+					}
+				}
+				sb.WriteByte(data[i])
+			default:
+				sb.WriteByte(data[i])
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	return "", 0, fmt.Errorf("unterminated string at offset %d", start)
+}
+
+var stringsEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+// quoteStringsValue encodes s as a quoted .strings value
+func quoteStringsValue(s string) string {
+	return `"` + stringsEscaper.Replace(s) + `"`
+}
+
+// decodeUTF16BOM converts UTF-16 data starting with a byte order mark to
+// UTF-8 and returns the encoding needed to convert it back. UTF-8 data is
+// returned unchanged with a nil encoding.
+func decodeUTF16BOM(data []byte) ([]byte, encoding.Encoding, error) {
+	var enc encoding.Encoding
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		enc = unicode.UTF16(unicode.LittleEndian, unicode.ExpectBOM)
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		enc = unicode.UTF16(unicode.BigEndian, unicode.ExpectBOM)
+	default:
+		return data, nil, nil
+	}
+
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid UTF-16 data: %v", err)
+	}
+
+	return text, enc, nil
+}
+
+// **************************************************************************
+// translateStringsdict translates the NSStringLocalizedFormatKey and the
+// plural forms of every entry of a .stringsdict property list. The key of
+// the entry is used as context.
+// --------------------------------------------------------------------------
+func translateStringsdict(data []byte) ([]byte, error) {
+	toks, err := scanXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []xmlEdit
+	var segs []segment
+	depth := 0
+	entry, lastKey := "", ""
+	for i := 0; i < len(toks); i++ {
+		switch tok := toks[i].tok.(type) {
+		case xml.StartElement:
+			switch tok.Name.Local {
+			case "dict":
+				depth++
+			case "key", "string":
+				j := xmlElementEnd(toks, i)
+				text := xmlCharData(toks[i+1 : j])
+				if tok.Name.Local == "key" {
+					lastKey = text
+					if depth == 1 {
+						entry = text
+					}
+				} else if depth > 1 && slices.Contains(stringsdictKeys, lastKey) {
+					edits = append(edits, xmlEdit{start: toks[i].end, end: toks[j].start})
+					segs = append(segs, segment{Text: text, Context: entry})
+				}
+				i = j
+			}
+		case xml.EndElement:
+			if tok.Name.Local == "dict" {
+				depth--
+			}
+		}
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(reIOSPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, text := range strOut {
+		edits[i].text = escapeXMLText(text)
+	}
+
+	return applyXMLEdits(data, edits), nil
+}
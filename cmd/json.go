@@ -0,0 +1,349 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Number of JSON Lines records translated together
+const jsonLinesBatch = 200
+
+// jsonCmd represents the json command
+var jsonCmd = &cobra.Command{
+	Use:   "json",
+	Short: "Translate selected fields of JSON and JSON Lines files",
+	Long: `Translates string values of arbitrary JSON documents and JSON Lines (.jsonl,
+.ndjson) files. Only the translated strings are rewritten; everything else,
+including the formatting, key order and number literals, is copied verbatim.
+
+Fields are chosen with --select, which accepts a subset of JSONPath:
+
+  $.items[*].description   description of every item
+  $.meta.title             a single field
+  $['display name']        keys with special characters
+  $.pages[0].body          array indexes (negative ones count from the end)
+  $..text                  text fields at any depth
+  text                     shorthand for $..text
+
+A selected object or array has all its strings translated. Without --select
+every string value is translated. JSON Lines files are processed record by
+record, so they do not have to fit in memory; use --jsonl for other extensions.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := make([][]jsonPathStep, 0, len(jsonSelect))
+		for _, expr := range jsonSelect {
+			steps, err := parseJSONPath(expr)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, steps)
+		}
+
+		ext := strings.ToLower(filepath.Ext(uncompressedName(inputFile)))
+		if jsonLines || ext == ".jsonl" || ext == ".ndjson" {
+			return runJSONLines(paths)
+		}
+
+		return runFileHandler(func(name string, data []byte) ([]byte, error) {
+			return translateJSON(data, paths)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(jsonCmd)
+
+	jsonCmd.Flags().StringArrayVarP(&jsonSelect, "select", "", []string{}, "Path of the fields to translate (can be specified multiple times)")
+	jsonCmd.Flags().BoolVarP(&jsonLines, "jsonl", "", false, "Treat the input as JSON Lines regardless of its extension")
+}
+
+// jsonPathStep is a step of a path expression
+type jsonPathStep struct {
+	key      string // Object key
+	index    int    // Array index, when key is empty
+	wildcard bool   // Any key or index
+	deep     bool   // Matches at any depth ("..")
+}
+
+// **************************************************************************
+// parseJSONPath parses a path expression like $.items[*].description or
+// $..title. An expression not starting with $ is the name of a field that
+// may appear at any depth.
+// --------------------------------------------------------------------------
+func parseJSONPath(expr string) ([]jsonPathStep, error) {
+	if !strings.HasPrefix(expr, "$") {
+		if expr == "" {
+			return nil, fmt.Errorf("empty path expression")
+		}
+		return []jsonPathStep{{key: expr, deep: true}}, nil
+	}
+
+	var steps []jsonPathStep
+	rest := expr[1:]
+	for rest != "" {
+		var step jsonPathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.deep = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid path expression %q: missing field name", expr)
+			}
+			step.key, step.wildcard = name, name == "*"
+			rest = rest[end:]
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("invalid path expression %q: unexpected %q", expr, rest)
+		}
+
+		if len(rest) > 1 && (rest[1] == '\'' || rest[1] == '"') {
+			// Quoted keys may contain ] and .
+			end := strings.Index(rest[2:], rest[1:2]+"]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path expression %q: unterminated key", expr)
+			}
+			step.key = rest[2 : end+2]
+			rest = rest[end+4:]
+			steps = append(steps, step)
+			continue
+		}
+
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("invalid path expression %q: missing ]", expr)
+		}
+		sel := strings.TrimSpace(rest[1:end])
+		if sel == "*" {
+			step.wildcard = true
+		} else if index, err := strconv.Atoi(sel); err == nil {
+			step.index = index
+		} else {
+			return nil, fmt.Errorf("invalid path expression %q: bad selector [%v]", expr, sel)
+		}
+		rest = rest[end+1:]
+		steps = append(steps, step)
+	}
+
+	return steps, nil
+}
+
+// matchJSONPath calls fn for every node reached by the steps from n
+func matchJSONPath(n *jsonNode, steps []jsonPathStep, fn func(*jsonNode)) {
+	if len(steps) == 0 {
+		fn(n)
+		return
+	}
+
+	step := steps[0]
+	for i, value := range n.values {
+		switch {
+		case step.wildcard:
+			matchJSONPath(value, steps[1:], fn)
+		case n.kind == jsonObject && step.key != "":
+			if n.keys[i] == step.key {
+				matchJSONPath(value, steps[1:], fn)
+			}
+		case n.kind == jsonArray && step.key == "":
+			if i == step.index || i == len(n.values)+step.index {
+				matchJSONPath(value, steps[1:], fn)
+			}
+		}
+		if step.deep {
+			matchJSONPath(value, steps, fn)
+		}
+	}
+}
+
+// selectJSONStrings returns the string nodes selected by any of the paths in
+// document order; without paths all strings are selected
+func selectJSONStrings(root *jsonNode, paths [][]jsonPathStep) []*jsonNode {
+	selected := map[*jsonNode]bool{root: len(paths) == 0}
+	for _, steps := range paths {
+		matchJSONPath(root, steps, func(n *jsonNode) { selected[n] = true })
+	}
+
+	var strs []*jsonNode
+	var walk func(n *jsonNode, inside bool)
+	walk = func(n *jsonNode, inside bool) {
+		inside = inside || selected[n]
+		if n.kind == jsonString && inside {
+			strs = append(strs, n)
+		}
+		for _, value := range n.values {
+			walk(value, inside)
+		}
+	}
+	walk(root, false)
+
+	return strs
+}
+
+// **************************************************************************
+// translateJSON translates the selected strings of a JSON document, replacing
+// only their literals in the source.
+// --------------------------------------------------------------------------
+func translateJSON(data []byte, paths [][]jsonPathStep) ([]byte, error) {
+	out, err := translateJSONRecords([][]byte{data}, 0, paths)
+	if err != nil {
+		return nil, err
+	}
+
+	return out[0], nil
+}
+
+// translateJSONRecords translates a batch of JSON documents. Blank records
+// are returned as they are. Errors name the line of the record when first,
+// the line of the first record, is positive.
+func translateJSONRecords(records [][]byte, first int, paths [][]jsonPathStep) ([][]byte, error) {
+	edits := make([][]xmlEdit, len(records))
+	var segs []segment
+	var owners []int
+	for i, data := range records {
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+		root, err := parseJSONTree(data)
+		if err != nil {
+			if first > 0 {
+				return nil, fmt.Errorf("line %d: %v", first+i, err)
+			}
+			return nil, err
+		}
+		for _, n := range selectJSONStrings(root, paths) {
+			edits[i] = append(edits[i], xmlEdit{start: n.start, end: n.end})
+			segs = append(segs, segment{Text: n.str})
+			owners = append(owners, i)
+		}
+	}
+
+	strOut, err := translateSegments(segs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([][]byte, len(records))
+	next := make([]int, len(records))
+	for k, text := range strOut {
+		i := owners[k]
+		var buf bytes.Buffer
+		writeJSONString(&buf, text)
+		edits[i][next[i]].text = buf.String()
+		next[i]++
+	}
+	for i, data := range records {
+		out[i] = applyXMLEdits(data, edits[i])
+	}
+
+	return out, nil
+}
+
+// **************************************************************************
+// runJSONLines translates a JSON Lines file record by record, reading and
+// writing it in batches of jsonLinesBatch records.
+// --------------------------------------------------------------------------
+func runJSONLines(paths [][]jsonPathStep) error {
+	if inputFile == outputFile {
+		return fmt.Errorf("input file and output file are the same: %v", inputFile)
+	}
+
+	// Start indicator:
+	shutdownCh := make(chan struct{})
+	go indicator(shutdownCh)
+
+	defer close(shutdownCh) // Signal indicator() to terminate
+
+	in, err := openInput(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input file: %v", err)
+	}
+	defer in.Close()
+
+	// Ensure the output directory exists
+	if err := os.MkdirAll(filepath.Dir(outputFile), 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	out, err := createOutput(outputFile)
+	if err != nil {
+		return fmt.Errorf("failed to create the output file: %v", err)
+	}
+	defer out.Close()
+
+	r := bufio.NewReader(in)
+	w := bufio.NewWriter(out)
+	var batch [][]byte
+	line := 0
+	flush := func() error {
+		translated, err := translateJSONRecords(batch, line-len(batch)+1, paths)
+		if err != nil {
+			return fmt.Errorf("failed to translate %v: %v", inputFile, err)
+		}
+		for _, record := range translated {
+			if _, err := w.Write(record); err != nil {
+				return fmt.Errorf("failed to write to the output file: %v", err)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	for {
+		record, err := r.ReadBytes('\n')
+		if len(record) > 0 {
+			batch = append(batch, record)
+			line++
+		}
+		if len(batch) == jsonLinesBatch || (err == io.EOF && len(batch) > 0) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read input file: %v", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("failed to write to the output file: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write to the output file: %v", err)
+	}
+
+	return nil
+}
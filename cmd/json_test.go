@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseJSONPath(t *testing.T) {
+	tests := []struct {
+		expr string
+		want []jsonPathStep
+	}{
+		{"text", []jsonPathStep{{key: "text", deep: true}}},
+		{"$", nil},
+		{"$.meta.title", []jsonPathStep{{key: "meta"}, {key: "title"}}},
+		{"$.items[*].description", []jsonPathStep{{key: "items"}, {wildcard: true}, {key: "description"}}},
+		{"$.pages[0].body", []jsonPathStep{{key: "pages"}, {index: 0}, {key: "body"}}},
+		{"$.pages[-1]", []jsonPathStep{{key: "pages"}, {index: -1}}},
+		{"$..text", []jsonPathStep{{key: "text", deep: true}}},
+		{"$..[*]", []jsonPathStep{{wildcard: true, deep: true}}},
+		{"$.*", []jsonPathStep{{key: "*", wildcard: true}}},
+		{"$['display name'][\"a.b]\"]", []jsonPathStep{{key: "display name"}, {key: "a.b]"}}},
+	}
+
+	for _, tt := range tests {
+		got, err := parseJSONPath(tt.expr)
+		if err != nil {
+			t.Errorf("parseJSONPath(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("parseJSONPath(%q) = %+v, want %+v", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestParseJSONPathErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "empty path expression"},
+		{"$.", "missing field name"},
+		{"$items", "unexpected"},
+		{"$['open", "unterminated key"},
+		{"$[1", "missing ]"},
+		{"$[a]", "bad selector [a]"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseJSONPath(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseJSONPath(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+func TestSelectJSONStrings(t *testing.T) {
+	data := `{"meta": {"title": "Feed", "id": "f1"}, "items": [` +
+		`{"text": "One", "tags": ["a", "b"]}, {"text": "Two", "note": {"text": "Deep"}}]}`
+	root, err := parseJSONTree([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		exprs []string
+		want  []string
+	}{
+		{nil, []string{"Feed", "f1", "One", "a", "b", "Two", "Deep"}},
+		{[]string{"$.meta.title"}, []string{"Feed"}},
+		{[]string{"$.items[*].text"}, []string{"One", "Two"}},
+		{[]string{"text"}, []string{"One", "Two", "Deep"}},
+		{[]string{"$.items[-1]"}, []string{"Two", "Deep"}},
+		{[]string{"$.items[0].tags", "$.meta.id"}, []string{"f1", "a", "b"}},
+		{[]string{"$.missing", "$.items[5]"}, nil},
+	}
+
+	for _, tt := range tests {
+		var paths [][]jsonPathStep
+		for _, expr := range tt.exprs {
+			steps, err := parseJSONPath(expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			paths = append(paths, steps)
+		}
+		var got []string
+		for _, n := range selectJSONStrings(root, paths) {
+			got = append(got, n.str)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%q selects %q, want %q", tt.exprs, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateJSONRecords(t *testing.T) {
+	fakeTranslate(t, strings.ToUpper)
+	steps, err := parseJSONPath("text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := [][]byte{[]byte(`{"id":"x1", "text":"Say \"hi\"\n"}`), []byte(""), []byte(`{"text": "café"}`)}
+	got, err := translateJSONRecords(records, 1, [][]jsonPathStep{steps})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`{"id":"x1", "text":"SAY \"HI\"\n"}`, "", `{"text": "CAFÉ"}`}
+	for i := range want {
+		if string(got[i]) != want[i] {
+			t.Errorf("record %d = %s, want %s", i, got[i], want[i])
+		}
+	}
+
+	records = append(records, []byte(`{"text": }`))
+	if _, err := translateJSONRecords(records, 10, [][]jsonPathStep{steps}); err == nil || !strings.HasPrefix(err.Error(), "line 13:") {
+		t.Errorf("translateJSONRecords() error = %v, want one naming line 13", err)
+	}
+}
@@ -0,0 +1,280 @@
+/*
+This file implements a minimal JSON document model that, unlike decoding into
+map[string]any, keeps the order of object keys and the exact text of number
+literals. Handlers use it to rewrite string values of JSON resource files
+while keeping the rest of the document, and therefore the diffs, stable.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+)
+
+// Kinds of JSON nodes
+const (
+	jsonObject = iota
+	jsonArray
+	jsonString
+	jsonLiteral // number, true, false or null
+)
+
+// jsonNode is a single value of a JSON document
+type jsonNode struct {
+	kind   int
+	keys   []string    // Object keys in document order
+	values []*jsonNode // Object values (parallel to keys) or array items
+	str    string      // Value of a string node
+	raw    string      // Source text of a literal node
+	start  int         // Byte range of a string node in the source,
+	end    int         // including the quotes
+}
+
+// jsonStyle describes the layout of a JSON document so that it can be
+// written back the same way
+type jsonStyle struct {
+	indent  string // Indentation unit; empty for compact documents
+	newline bool   // Whether the document ends with a newline
+}
+
+// **************************************************************************
+// parseJSONTree parses a complete JSON document. Trailing data after the
+// top-level value is reported as an error.
+// --------------------------------------------------------------------------
+func parseJSONTree(data []byte) (*jsonNode, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+
+	node, err := decodeJSONNode(dec)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("invalid JSON: unexpected data after the top-level value")
+	}
+	node.locate(data)
+
+	return node, nil
+}
+
+func decodeJSONNode(dec *json.Decoder) (*jsonNode, error) {
+	start := int(dec.InputOffset())
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		node := &jsonNode{kind: jsonArray}
+		if t == '{' {
+			node.kind = jsonObject
+		}
+		for dec.More() {
+			if node.kind == jsonObject {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				node.keys = append(node.keys, key.(string))
+			}
+			value, err := decodeJSONNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			node.values = append(node.values, value)
+		}
+		// Consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return nil, err
+		}
+		return node, nil
+	case string:
+		return &jsonNode{kind: jsonString, str: t, start: start, end: int(dec.InputOffset())}, nil
+	case json.Number:
+		return &jsonNode{kind: jsonLiteral, raw: string(t)}, nil
+	case bool:
+		return &jsonNode{kind: jsonLiteral, raw: strconv.FormatBool(t)}, nil
+	default:
+		return &jsonNode{kind: jsonLiteral, raw: "null"}, nil
+	}
+}
+
+// locate moves the start of string nodes past the whitespace and separators
+// the decoder reports as part of the token
+func (n *jsonNode) locate(data []byte) {
+	if n.kind == jsonString {
+		n.start += bytes.IndexByte(data[n.start:n.end], '"')
+	}
+	for _, value := range n.values {
+		value.locate(data)
+	}
+}
+
+// get returns the value stored under key in an object node, or nil
+func (n *jsonNode) get(key string) *jsonNode {
+	if n == nil || n.kind != jsonObject {
+		return nil
+	}
+	for i, k := range n.keys {
+		if k == key {
+			return n.values[i]
+		}
+	}
+
+	return nil
+}
+
+// walkJSONStrings calls fn for every string value below node with the path
+// of object keys and array indices leading to it
+func walkJSONStrings(node *jsonNode, path []string, fn func(path []string, node *jsonNode)) {
+	switch node.kind {
+	case jsonString:
+		fn(path, node)
+	case jsonObject:
+		for i, value := range node.values {
+			walkJSONStrings(value, append(path[:len(path):len(path)], node.keys[i]), fn)
+		}
+	case jsonArray:
+		for i, value := range node.values {
+			walkJSONStrings(value, append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+		}
+	}
+}
+
+// set stores value under key in an object node. A new key is inserted after
+// the key named after, or appended when there is no such key.
+func (n *jsonNode) set(key string, value *jsonNode, after string) {
+	pos := len(n.keys)
+	for i, k := range n.keys {
+		if k == key {
+			n.values[i] = value
+			return
+		}
+		if k == after {
+			pos = i + 1
+		}
+	}
+
+	n.keys = slices.Insert(n.keys, pos, key)
+	n.values = slices.Insert(n.values, pos, value)
+}
+
+// clone returns a copy of the node that can be modified without affecting
+// the original
+func (n *jsonNode) clone() *jsonNode {
+	c := *n
+	c.keys = slices.Clone(n.keys)
+	c.values = make([]*jsonNode, len(n.values))
+	for i, value := range n.values {
+		c.values[i] = value.clone()
+	}
+
+	return &c
+}
+
+// **************************************************************************
+// splice returns the source data of the tree with the string values that
+// have changed written over their original text, keeping the rest of the
+// document, down to inline objects and escapes, as it was. Nodes added to
+// the tree are not written; use encode then.
+// --------------------------------------------------------------------------
+func (n *jsonNode) splice(data []byte) []byte {
+	var edits []xmlEdit
+	walkJSONStrings(n, nil, func(path []string, node *jsonNode) {
+		var old string
+		if node.end == 0 || json.Unmarshal(data[node.start:node.end], &old) != nil || old == node.str {
+			return
+		}
+		var buf bytes.Buffer
+		writeJSONString(&buf, node.str)
+		edits = append(edits, xmlEdit{start: node.start, end: node.end, text: buf.String()})
+	})
+
+	return applyXMLEdits(data, edits)
+}
+
+// detectJSONStyle guesses the indentation unit from the first indented line
+func detectJSONStyle(data []byte) jsonStyle {
+	style := jsonStyle{newline: bytes.HasSuffix(data, []byte("\n"))}
+
+	body := bytes.TrimSpace(data)
+	if i := bytes.IndexByte(body, '\n'); i >= 0 {
+		rest := body[i+1:]
+		n := 0
+		for n < len(rest) && (rest[n] == ' ' || rest[n] == '\t') {
+			n++
+		}
+		style.indent = string(rest[:n])
+		if style.indent == "" {
+			style.indent = "  "
+		}
+	}
+
+	return style
+}
+
+// **************************************************************************
+// encode serializes the node using the given style. Strings are written
+// without HTML escaping so that markup in translations stays readable.
+// --------------------------------------------------------------------------
+func (n *jsonNode) encode(style jsonStyle) []byte {
+	var buf bytes.Buffer
+	n.write(&buf, style.indent, "")
+	if style.newline {
+		buf.WriteByte('\n')
+	}
+
+	return buf.Bytes()
+}
+
+func (n *jsonNode) write(buf *bytes.Buffer, indent, prefix string) {
+	switch n.kind {
+	case jsonString:
+		writeJSONString(buf, n.str)
+	case jsonLiteral:
+		buf.WriteString(n.raw)
+	default:
+		open, close := byte('['), byte(']')
+		if n.kind == jsonObject {
+			open, close = '{', '}'
+		}
+
+		buf.WriteByte(open)
+		inner := prefix + indent
+		for i, value := range n.values {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if indent != "" {
+				buf.WriteByte('\n')
+				buf.WriteString(inner)
+			}
+			if n.kind == jsonObject {
+				writeJSONString(buf, n.keys[i])
+				buf.WriteByte(':')
+				if indent != "" {
+					buf.WriteByte(' ')
+				}
+			}
+			value.write(buf, indent, inner)
+		}
+		if indent != "" && len(n.values) > 0 {
+			buf.WriteByte('\n')
+			buf.WriteString(prefix)
+		}
+		buf.WriteByte(close)
+	}
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	enc.Encode(s)
+	// Encode always terminates the value with a newline
+	buf.Truncate(buf.Len() - 1)
+}
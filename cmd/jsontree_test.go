@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJSONNodeOffsets(t *testing.T) {
+	data := `{"a": "x", "b" :  ["y", 1, {"c":"é\"z"}], "d": null,
+	  "e":"hé"}`
+	tree, err := parseJSONTree([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	walkJSONStrings(tree, nil, func(path []string, node *jsonNode) {
+		got = append(got, strings.Join(path, ".")+"="+data[node.start:node.end])
+	})
+	want := []string{`a="x"`, `b.0="y"`, `b.2.c="é\"z"`, `e="hé"`}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("string nodes %q, want %q", got, want)
+	}
+}
+
+func TestJSONNodeSplice(t *testing.T) {
+	tests := []struct {
+		data string
+		set  map[string]string
+		want string
+	}{
+		{
+			data: "{\n  \"title\": \"Hello\",\n  \"@title\": {\"placeholders\": {\"n\": {}}},\n  \"n\": 1.50\n}\n",
+			set:  map[string]string{"title": "Привіт"},
+			want: "{\n  \"title\": \"Привіт\",\n  \"@title\": {\"placeholders\": {\"n\": {}}},\n  \"n\": 1.50\n}\n",
+		},
+		{
+			data: `{"a":"café","b":"x"}`,
+			set:  map[string]string{"b": `say "<b>hi</b>"`},
+			want: `{"a":"café","b":"say \"<b>hi</b>\""}`,
+		},
+		{
+			data: `["same", "other"]`,
+			set:  map[string]string{"0": "same"},
+			want: `["same", "other"]`,
+		},
+	}
+
+	for _, tt := range tests {
+		tree, err := parseJSONTree([]byte(tt.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		walkJSONStrings(tree, nil, func(path []string, node *jsonNode) {
+			if text, ok := tt.set[strings.Join(path, ".")]; ok {
+				node.str = text
+			}
+		})
+		if got := string(tree.splice([]byte(tt.data))); got != tt.want {
+			t.Errorf("splice(%q) = %q, want %q", tt.data, got, tt.want)
+		}
+	}
+}
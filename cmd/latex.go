@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Inline math, commands whose arguments are labels, keys or paths (kept
+	// with their arguments), other commands up to their opening brace, escaped
+	// characters, braces, alignment tabs and ties. The text in the arguments of
+	// commands like \emph{...} or \section{...} is translated.
+	reLaTeXPlaceholder = regexp.MustCompile(`(?s)\$\$.*?\$\$|\$(?:\\.|[^$\\])*\$|\\\(.*?\\\)|\\\[.*?\\\]|` +
+		`\\(?:ref|eqref|autoref|[cC]ref|pageref|cite[a-zA-Z]*|label|url|includegraphics|input|include|href|begin|end|` +
+		`usepackage|documentclass|bibliography[a-z]*|[hv]space|setlength|color)\*?(?:\[[^\]]*\])*\{[^{}]*\}|` +
+		`\\[a-zA-Z@]+\*?(?:\[[^\]]*\])*\{?|\\.|[{}&~]`)
+
+	// Delimiters of display math
+	laTeXDisplayMath = map[string]string{`\[`: `\]`, `$$`: `$$`}
+
+	// Start of an environment
+	reLaTeXBegin = regexp.MustCompile(`^\\begin\{([^}]+)\}`)
+	// End of an environment
+	reLaTeXEnd = regexp.MustCompile(`^\\end\{([^}]+)\}`)
+	// List items
+	reLaTeXItem = regexp.MustCompile(`^(\s*\\item(?:\[[^\]]*\])?\s*)(.*)$`)
+	// Name of the command starting a line
+	reLaTeXCommand = regexp.MustCompile(`^\\([a-zA-Z]+)`)
+
+	// Environments whose content is code or math
+	laTeXSkipEnvironments = []string{
+		"verbatim", "Verbatim", "lstlisting", "minted", "comment", "filecontents", "tikzpicture",
+		"equation", "equation*", "align", "align*", "alignat", "alignat*", "gather", "gather*",
+		"multline", "multline*", "flalign", "flalign*", "eqnarray", "eqnarray*", "displaymath", "math",
+		"algorithmic",
+	}
+	// Environments whose rows are translated one line at a time
+	laTeXTableEnvironments = []string{"tabular", "tabular*", "tabularx", "longtable", "tabulary"}
+	// Commands that may appear inside a paragraph; a line starting with any
+	// other command stands on its own
+	laTeXInlineCommands = []string{
+		"emph", "textbf", "textit", "texttt", "textsc", "textsf", "textrm", "textup", "textsl", "textmd",
+		"underline", "footnote", "enquote", "mbox", "url", "href", "ref", "eqref", "autoref", "cref", "Cref",
+		"pageref", "cite", "citep", "citet", "citeauthor", "ldots", "dots", "LaTeX", "TeX", "today",
+	}
+)
+
+// latexCmd represents the latex command
+var latexCmd = &cobra.Command{
+	Use:   "latex",
+	Short: "Translate LaTeX documents",
+	Long: `Translates the prose of LaTeX documents (.tex): paragraphs, sectioning
+commands, captions, footnotes, list items and table cells. The preamble, math
+(inline, display and environments like equation or align), verbatim and code
+listings, and comments are copied unchanged. Commands are kept, while the text
+of their arguments is translated, except for references, citations, labels,
+URLs and file names.
+
+Files without \begin{document}, like chapters pulled in with \input, are
+translated from the first line. Paragraphs spanning several lines are
+re-wrapped to the width of their longest line.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(proseHandler(scanLaTeX, reLaTeXPlaceholder))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(latexCmd)
+	addEncodingFlags(latexCmd)
+}
+
+// **************************************************************************
+// scanLaTeX returns the paragraphs of the body of a LaTeX document. A line
+// starting with a command that is not inline markup, like \section or
+// \caption, is a paragraph of its own, and so is every row of a table. A
+// paragraph ends at a line with a comment, which is kept after the text.
+// --------------------------------------------------------------------------
+func scanLaTeX(lines []string) []proseParagraph {
+	var paras []proseParagraph
+	var cur *proseParagraph
+	end := func() {
+		if cur != nil {
+			paras = append(paras, *cur)
+			cur = nil
+		}
+	}
+
+	first := 0
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), `\begin{document}`) {
+			first = i + 1
+			break
+		}
+	}
+
+	skipEnd := "" // Text closing the math or code being skipped
+	tables := 0   // Depth of nested tables
+	for i := first; i < len(lines); i++ {
+		line := lines[i]
+		if skipEnd != "" {
+			if strings.Contains(line, skipEnd) {
+				skipEnd = ""
+			}
+			continue
+		}
+
+		code, comment := splitLaTeXComment(line)
+		trimmed := strings.TrimSpace(code)
+		if strings.HasPrefix(trimmed, `\end{document}`) {
+			break
+		}
+
+		if m := reLaTeXBegin.FindStringSubmatch(trimmed); m != nil {
+			switch {
+			case slices.Contains(laTeXSkipEnvironments, m[1]):
+				end()
+				if !strings.Contains(trimmed, `\end{`+m[1]+`}`) {
+					skipEnd = `\end{` + m[1] + `}`
+				}
+				continue
+			case slices.Contains(laTeXTableEnvironments, m[1]):
+				tables++
+			}
+		}
+		if m := reLaTeXEnd.FindStringSubmatch(trimmed); m != nil && slices.Contains(laTeXTableEnvironments, m[1]) {
+			tables = max(tables-1, 0)
+		}
+		if reLaTeXBegin.MatchString(trimmed) || reLaTeXEnd.MatchString(trimmed) {
+			end()
+			continue
+		}
+		for open, close := range laTeXDisplayMath {
+			if strings.HasPrefix(trimmed, open) && !strings.Contains(trimmed[len(open):], close) {
+				end()
+				skipEnd = close
+			}
+		}
+		if skipEnd != "" {
+			continue
+		}
+
+		indent := leadingSpace(code)
+		closed := comment != "" || tables > 0 // Whether the paragraph ends at this line
+		m := reLaTeXCommand.FindStringSubmatch(trimmed)
+		switch {
+		case trimmed == "":
+			end()
+			continue
+		case tables > 0 || m != nil && !slices.Contains(laTeXInlineCommands, m[1]) && !reLaTeXItem.MatchString(code):
+			end()
+			cur = &proseParagraph{prefix: indent, indent: indent}
+			cur.add(i, trimmed)
+			closed = closed || strings.Count(trimmed, "{") == strings.Count(trimmed, "}")
+		case reLaTeXItem.MatchString(code):
+			end()
+			item := reLaTeXItem.FindStringSubmatch(code)
+			cur = &proseParagraph{prefix: item[1], indent: indent + "  "}
+			cur.add(i, item[2])
+		default:
+			if cur == nil {
+				cur = &proseParagraph{prefix: indent, indent: indent}
+			}
+			cur.add(i, trimmed)
+		}
+		if closed {
+			cur.suffix = comment
+			end()
+		}
+	}
+	end()
+
+	return paras
+}
+
+// splitLaTeXComment splits a line at the first % that is not escaped; the
+// comment keeps the spaces before it
+func splitLaTeXComment(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		switch line[i] {
+		case '\\':
+			i++
+		case '%':
+			code := strings.TrimRight(line[:i], " \t")
+			return code, line[len(code):]
+		}
+	}
+
+	return line, ""
+}
@@ -0,0 +1,207 @@
+/*
+This file implements the scanners of Markdown text and of # comments in code
+for the line-based prose model. The Markdown scanner follows CommonMark as far
+as block structure goes: fenced and indented code, display math, HTML blocks
+and link reference definitions are kept, while headings, paragraphs, list
+items, block quotes and table rows are prose.
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	// Inline markup of Markdown: code spans, math, images, link targets, HTML
+	// tags, autolinks, URLs and table separators
+	reMarkdownPlaceholder = regexp.MustCompile("(?s)``.*?``|`[^`]*`|\\$\\$.*?\\$\\$|\\$[^$\\s](?:[^$]*[^$\\s])?\\$|\\\\\\(.*?\\\\\\)|" +
+		"!\\[[^\\]]*\\]\\([^)]*\\)|\\]\\([^)]*\\)|\\]\\[[^\\]]*\\]|<[^<>\\s][^<>]*>|https?://[^\\s)<>]+|\\|")
+	// Code spans and URLs of code comments
+	reCodeCommentPlaceholder = regexp.MustCompile("`[^`]*`|https?://\\S+")
+
+	// ATX headings, with their optional closing sequence
+	reMarkdownHeading = regexp.MustCompile(`^(\s{0,3}#{1,6}\s+)(.*?)(\s+#+\s*)?$`)
+	// Bullet and ordered list items, task list items included
+	reMarkdownItem = regexp.MustCompile(`^(\s*(?:[-*+]|\d+[.)])\s+(?:\[[ xX]\]\s+)?)(.*)$`)
+	// Block quotes
+	reMarkdownQuote = regexp.MustCompile(`^(\s*(?:>\s?)+)(.*)$`)
+	// Setext heading underlines and thematic breaks
+	reMarkdownRule = regexp.MustCompile(`^(?:=+|-+|(?:\*\s*){3,}|(?:_\s*){3,}|(?:-\s*){3,})$`)
+	// Link reference definitions
+	reMarkdownLinkDef = regexp.MustCompile(`^\s{0,3}\[[^\]]+\]:\s`)
+	// Delimiter rows of tables
+	reMarkdownTableRule = regexp.MustCompile(`^\|?\s*:?-+:?\s*(?:\|\s*:?-+:?\s*)*\|?$`)
+
+	// Comment lines and trailing comments that are code commented out
+	reCommentedCode = regexp.MustCompile(`^(?:import |from \S+ import |print\(|return\b|def |class |[\w.\[\]'"]+\s*(?:[-+*/]?=|\())`)
+)
+
+// **************************************************************************
+// scanMarkdown returns the paragraphs of Markdown text. Headings and table
+// rows take one line each; list items and block quotes continue over their
+// lazy continuation lines.
+// --------------------------------------------------------------------------
+func scanMarkdown(lines []string) []proseParagraph {
+	var paras []proseParagraph
+	var cur *proseParagraph
+	end := func() {
+		if cur != nil {
+			paras = append(paras, *cur)
+			cur = nil
+		}
+	}
+	single := func(i int, prefix, text, suffix string) {
+		end()
+		p := proseParagraph{prefix: prefix, suffix: suffix}
+		p.add(i, text)
+		paras = append(paras, p)
+	}
+
+	fence := ""          // Closing fence of the code or math being skipped
+	skipToBlank := false // Whether an HTML block or indented code is being skipped
+	table := false       // Whether the lines are table rows
+	list := false        // Whether indented lines belong to a list item
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		width := len(leadingSpace(line))
+		switch {
+		case fence != "":
+			if strings.HasPrefix(trimmed, fence) {
+				fence = ""
+			}
+			continue
+		case skipToBlank:
+			skipToBlank = trimmed != ""
+			continue
+		}
+
+		var m []string
+		switch {
+		case trimmed == "":
+			end()
+			table = false
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			end()
+			fence = trimmed[:3]
+		case strings.HasPrefix(trimmed, "$$"):
+			end()
+			if len(trimmed) < 4 || !strings.HasSuffix(trimmed, "$$") {
+				fence = "$$"
+			}
+		case width >= 4 && cur == nil && !list:
+			skipToBlank = true
+		case strings.HasPrefix(trimmed, "<") || strings.HasPrefix(trimmed, `\begin{`) || reMarkdownLinkDef.MatchString(line):
+			end()
+			skipToBlank = true
+		case reMarkdownRule.MatchString(trimmed):
+			end()
+		case table || strings.Contains(line, "|") && i+1 < len(lines) && isMarkdownTableRule(lines[i+1]):
+			table = true
+			single(i, leadingSpace(line), trimmed, "")
+		default:
+			if m = reMarkdownHeading.FindStringSubmatch(line); m != nil {
+				single(i, m[1], m[2], m[3])
+			} else if m = reMarkdownItem.FindStringSubmatch(line); m != nil {
+				end()
+				cur = &proseParagraph{prefix: m[1], indent: strings.Repeat(" ", len(m[1]))}
+				cur.add(i, m[2])
+				list = true
+			} else if m = reMarkdownQuote.FindStringSubmatch(line); m != nil && (cur == nil || cur.prefix != m[1]) {
+				end()
+				cur = &proseParagraph{prefix: m[1], indent: m[1]}
+				cur.add(i, m[2])
+			} else {
+				if m != nil {
+					trimmed = m[2]
+				}
+				if cur == nil {
+					cur = &proseParagraph{prefix: leadingSpace(line), indent: leadingSpace(line)}
+					list = list && width > 0
+				}
+				cur.add(i, trimmed)
+			}
+		}
+	}
+	end()
+
+	return paras
+}
+
+// isMarkdownTableRule reports whether the line is the delimiter row of a table
+func isMarkdownTableRule(line string) bool {
+	return strings.Contains(line, "|") && reMarkdownTableRule.MatchString(strings.TrimSpace(line))
+}
+
+// **************************************************************************
+// scanHashComments returns the comments of code in languages with # comments
+// (Python, R, Julia, shell). Consecutive comment lines with the same
+// indentation form a paragraph; a comment after code is a paragraph of its
+// own. Shebangs, cell markers like # %% and code commented out are kept.
+// --------------------------------------------------------------------------
+func scanHashComments(lines []string) []proseParagraph {
+	var paras []proseParagraph
+	var cur *proseParagraph
+	end := func() {
+		if cur != nil {
+			paras = append(paras, *cur)
+			cur = nil
+		}
+	}
+
+	for i, line := range lines {
+		at := hashCommentStart(line)
+		if at < 0 {
+			end()
+			continue
+		}
+		marker := at + len(line[at:]) - len(strings.TrimLeft(line[at:], "#"))
+		text := strings.TrimSpace(line[marker:])
+		prefix := line[:len(line)-len(strings.TrimLeft(line[marker:], " \t"))]
+		if strings.HasPrefix(line[at:], "#!") || strings.HasPrefix(text, "%%") || text == "" || reCommentedCode.MatchString(text) {
+			end()
+			continue
+		}
+
+		if strings.TrimSpace(line[:at]) != "" {
+			// Trailing comment
+			end()
+			p := proseParagraph{prefix: prefix}
+			p.add(i, text)
+			paras = append(paras, p)
+			continue
+		}
+		if cur == nil || cur.prefix != prefix {
+			end()
+			cur = &proseParagraph{prefix: prefix, indent: prefix}
+		}
+		cur.add(i, text)
+	}
+	end()
+
+	return paras
+}
+
+// hashCommentStart returns the offset of the # starting a comment on the
+// line, or -1. Quoted strings are skipped; strings spanning lines are not
+// recognized.
+func hashCommentStart(line string) int {
+	quote := byte(0)
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0 && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '#':
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,230 @@
+/*
+This file splits XML documents with mixed content (XHTML chapters of e-books,
+OpenDocument text) into paragraphs for translation. A paragraph is the text of
+a block element up to its nested blocks; the character data inside inline
+elements forms the pieces of a rich paragraph, so the inline markup stays
+exactly where it was and only the text between the tags is replaced.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"html"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// markupRoles describes how the elements of an XML vocabulary are treated.
+// Elements are looked up by qualified name, or by local name when local is
+// set; elements that are neither blocks nor skipped are inline.
+type markupRoles struct {
+	local bool
+	block func(name string) bool // Elements that start a paragraph
+	seps  map[string]string      // Inline elements rendering whitespace, as HTML
+	skip  map[string]bool        // Elements whose content is not translated
+}
+
+var (
+	// Inline elements of XHTML; all other elements are blocks
+	xhtmlInline = map[string]bool{
+		"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "big": true,
+		"br": true, "cite": true, "code": true, "data": true, "del": true, "dfn": true,
+		"em": true, "font": true, "i": true, "img": true, "ins": true, "kbd": true,
+		"label": true, "mark": true, "q": true, "rp": true, "rt": true, "ruby": true,
+		"s": true, "samp": true, "small": true, "span": true, "strong": true, "sub": true,
+		"sup": true, "time": true, "tt": true, "u": true, "var": true, "wbr": true,
+	}
+	xhtmlRoles = markupRoles{
+		local: true,
+		block: func(name string) bool { return !xhtmlInline[name] },
+		seps:  map[string]string{"br": "<br>"},
+		skip: map[string]bool{
+			"script": true, "style": true, "pre": true, "code": true, "kbd": true,
+			"samp": true, "var": true, "math": true, "svg": true,
+		},
+	}
+
+	// Language attributes of a root element
+	reLangAttr = regexp.MustCompile(`(\s(?:xml:)?lang\s*=\s*)(?:"[^"]*"|'[^']*')`)
+)
+
+// markupParagraph is a paragraph of a markup document with the indexes of the
+// character data tokens holding its pieces
+type markupParagraph struct {
+	richParagraph
+	units []int
+}
+
+// markupDoc is a parsed markup document
+type markupDoc struct {
+	data  []byte
+	toks  []xmlToken
+	paras []*markupParagraph
+}
+
+// **************************************************************************
+// parseMarkup collects the paragraphs of a document. The content of skipped
+// elements is sent along as untranslatable text so that the translation
+// still sees the whole sentence, e.g. the command in "Run <code>ls</code>
+// to list files".
+// --------------------------------------------------------------------------
+func parseMarkup(data []byte, roles markupRoles) (*markupDoc, error) {
+	toks, err := scanXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	name := func(n xml.Name) string {
+		if roles.local {
+			return n.Local
+		}
+		return qualifiedName(n)
+	}
+
+	doc := &markupDoc{data: data, toks: toks}
+	var stack []*markupParagraph
+	addSep := func(sep string) {
+		if len(stack) == 0 {
+			return
+		}
+		p := stack[len(stack)-1]
+		if n := len(p.seps); n > 0 {
+			p.seps[n-1] += sep
+		}
+	}
+	for i := 0; i < len(toks); i++ {
+		switch tok := toks[i].tok.(type) {
+		case xml.StartElement:
+			n := name(tok.Name)
+			switch {
+			case roles.skip[n]:
+				j := xmlElementEnd(toks, i)
+				if text := xmlCharData(toks[i+1 : j]); text != "" {
+					addSep(`<span translate="no">` + html.EscapeString(text) + `</span>`)
+				}
+				i = j
+			case roles.block(n):
+				stack = append(stack, &markupParagraph{})
+			default:
+				addSep(roles.seps[n])
+			}
+		case xml.EndElement:
+			if !roles.block(name(tok.Name)) || len(stack) == 0 {
+				break
+			}
+			p := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(p.units) > 0 {
+				doc.paras = append(doc.paras, p)
+			}
+		case xml.CharData:
+			if len(stack) == 0 {
+				break
+			}
+			p := stack[len(stack)-1]
+			p.units = append(p.units, i)
+			p.pieces = append(p.pieces, string(tok))
+			p.seps = append(p.seps, "")
+		case xmlEntityRef:
+			addSep(tok.html())
+		}
+	}
+
+	return doc, nil
+}
+
+// html returns the entity reference as untranslatable HTML, so that the
+// translation sees it as part of the sentence
+func (ref xmlEntityRef) html() string {
+	return `<span translate="no">&amp;` + html.EscapeString(string(ref)) + `;</span>`
+}
+
+// richParagraphs returns the paragraphs of the documents for translation
+func richParagraphs(docs ...*markupDoc) []*richParagraph {
+	var paras []*richParagraph
+	for _, doc := range docs {
+		for _, p := range doc.paras {
+			paras = append(paras, &p.richParagraph)
+		}
+	}
+
+	return paras
+}
+
+// rewrite returns the document with the translated pieces and the extra
+// edits applied
+func (doc *markupDoc) rewrite(extra ...xmlEdit) []byte {
+	edits := extra
+	for _, p := range doc.paras {
+		for k, i := range p.units {
+			if p.pieces[k] == string(doc.toks[i].tok.(xml.CharData)) {
+				continue
+			}
+			t := doc.toks[i]
+			text := escapeXMLText(p.pieces[k])
+			if bytes.HasPrefix(doc.data[t.start:t.end], []byte("<![CDATA[")) {
+				// Keep CDATA sections, splitting any "]]>" in the text
+				text = "<![CDATA[" + strings.ReplaceAll(p.pieces[k], "]]>", "]]]]><![CDATA[>") + "]]>"
+			}
+			edits = append(edits, xmlEdit{start: t.start, end: t.end, text: text})
+		}
+	}
+
+	return applyXMLEdits(doc.data, edits)
+}
+
+// rootLangEdit returns the edit setting the xml:lang and lang attributes of
+// the root element to lang, if it has any
+func (doc *markupDoc) rootLangEdit(lang string) []xmlEdit {
+	root := xmlRootElement(doc.toks)
+	if root < 0 {
+		return nil
+	}
+
+	t := doc.toks[root]
+	tag := string(doc.data[t.start:t.end])
+	if !reLangAttr.MatchString(tag) {
+		return nil
+	}
+
+	return []xmlEdit{{start: t.start, end: t.end, text: reLangAttr.ReplaceAllString(tag, `${1}"`+lang+`"`)}}
+}
+
+// elementTextEdits returns edits replacing the text of every element with
+// the given qualified name, e.g. dc:language
+func (doc *markupDoc) elementTextEdits(qname, text string) []xmlEdit {
+	var edits []xmlEdit
+	for i, t := range doc.toks {
+		el, ok := t.tok.(xml.StartElement)
+		if !ok || qualifiedName(el.Name) != qname {
+			continue
+		}
+		j := xmlElementEnd(doc.toks, i)
+		if j == i+1 && strings.HasSuffix(string(doc.data[t.start:t.end]), "/>") {
+			continue
+		}
+		edits = append(edits, xmlEdit{start: t.end, end: doc.toks[j].start, text: escapeXMLText(text)})
+	}
+
+	return edits
+}
+
+// splitLanguageTag returns the language and region subtags of a BCP 47 tag;
+// the region is empty when the tag has none
+func splitLanguageTag(lang string) (string, string) {
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return lang, ""
+	}
+
+	base, _ := tag.Base()
+	region, conf := tag.Region()
+	if conf != language.Exact {
+		return base.String(), ""
+	}
+
+	return base.String(), region.String()
+}
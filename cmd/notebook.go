@@ -0,0 +1,164 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Kernel languages whose comments start with #
+var hashCommentLanguages = []string{"python", "r", "julia", "bash", "sh", "ruby", "perl"}
+
+// notebookCmd represents the notebook command
+var notebookCmd = &cobra.Command{
+	Use:   "notebook",
+	Short: "Translate Markdown cells of Jupyter notebooks",
+	Long: `Translates the Markdown cells of a Jupyter notebook (.ipynb) and, with
+--comments, the comments of its code cells. Code spans, fenced code, math
+($...$, $$...$$), images, link targets and HTML in Markdown are kept.
+
+Only the sources of the translated cells are rewritten, so outputs, metadata
+and the layout of the notebook JSON stay as they are. Comments are translated
+for kernels with # comments (Python, R, Julia, shell); code commented out is
+left alone.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateNotebook)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(notebookCmd)
+
+	notebookCmd.Flags().BoolVarP(&notebookComments, "comments", "", false, "Translate comments of code cells")
+}
+
+// notebookSource is the source of a cell and its lines
+type notebookSource struct {
+	node *jsonNode
+	text string
+	doc  *proseDoc
+}
+
+// **************************************************************************
+// translateNotebook is the fileHandler of the notebook command.
+// --------------------------------------------------------------------------
+func translateNotebook(name string, data []byte) ([]byte, error) {
+	root, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+	cells := root.get("cells")
+	if cells == nil || cells.kind != jsonArray {
+		return nil, fmt.Errorf("not a Jupyter notebook: no cells array")
+	}
+
+	lang := "python"
+	metadata := root.get("metadata")
+	if info := metadata.get("language_info").get("name"); info != nil && info.kind == jsonString {
+		lang = strings.ToLower(info.str)
+	} else if spec := metadata.get("kernelspec").get("language"); spec != nil && spec.kind == jsonString {
+		lang = strings.ToLower(spec.str)
+	}
+	comments := notebookComments && slices.Contains(hashCommentLanguages, lang)
+
+	var sources []*notebookSource
+	var markdown, code []*proseDoc
+	for _, cell := range cells.values {
+		kind, source := cell.get("cell_type"), cell.get("source")
+		if kind == nil || source == nil {
+			continue
+		}
+		s := &notebookSource{node: source, text: notebookSourceText(source)}
+		switch {
+		case kind.str == "markdown":
+			s.doc = newProseDoc(s.text, scanMarkdown)
+			markdown = append(markdown, s.doc)
+		case kind.str == "code" && comments:
+			s.doc = newProseDoc(s.text, scanHashComments)
+			code = append(code, s.doc)
+		default:
+			continue
+		}
+		sources = append(sources, s)
+	}
+
+	if err := translateProseDocs(markdown, reMarkdownPlaceholder); err != nil {
+		return nil, err
+	}
+	if err := translateProseDocs(code, reCodeCommentPlaceholder); err != nil {
+		return nil, err
+	}
+
+	var edits []xmlEdit
+	for _, s := range sources {
+		if text := strings.Join(s.doc.lines, "\n"); text != s.text {
+			edits = append(edits, notebookSourceEdit(data, s.node, text))
+		}
+	}
+
+	return applyXMLEdits(data, edits), nil
+}
+
+// notebookSourceText returns the text of a cell source, which is either a
+// string or an array of lines
+func notebookSourceText(source *jsonNode) string {
+	if source.kind == jsonString {
+		return source.str
+	}
+
+	var sb strings.Builder
+	for _, line := range source.values {
+		sb.WriteString(line.str)
+	}
+
+	return sb.String()
+}
+
+// notebookSourceEdit returns the edit replacing a cell source with text. An
+// array of lines is rewritten from its first to its last string, with the
+// separator of its first two lines or, for a single line, a line break and
+// the indentation of that line.
+func notebookSourceEdit(data []byte, source *jsonNode, text string) xmlEdit {
+	var buf bytes.Buffer
+	if source.kind == jsonString {
+		writeJSONString(&buf, text)
+		return xmlEdit{start: source.start, end: source.end, text: buf.String()}
+	}
+
+	first, last := source.values[0], source.values[len(source.values)-1]
+	sep := ",\n" + leadingSpace(string(data[bytes.LastIndexByte(data[:first.start], '\n')+1:first.start]))
+	if len(source.values) > 1 {
+		sep = string(data[first.end:source.values[1].start])
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		if i > 0 {
+			buf.WriteString(sep)
+		}
+		writeJSONString(&buf, line)
+	}
+
+	return xmlEdit{start: first.start, end: last.end, text: buf.String()}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTranslateNotebook(t *testing.T) {
+	defer func(comments bool) { notebookComments = comments }(notebookComments)
+	fakeTranslate(t, strings.ToUpper)
+
+	data := `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": [
+    "# Load the data\n",
+    "\n",
+    "Call ` + "`load()`" + ` first."
+   ]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 1,
+   "metadata": {},
+   "outputs": [{"output_type": "stream", "text": ["done\n"]}],
+   "source": [
+    "# Read the file\n",
+    "# print(data)\n",
+    "data = load()"
+   ]
+  },
+  {
+   "cell_type": "markdown",
+   "metadata": {},
+   "source": "One line"
+  }
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+	markdown := strings.NewReplacer("Load the data", "LOAD THE DATA", "first.", "FIRST.", "Call", "CALL",
+		`"source": "One line"`, `"source": "ONE LINE"`)
+	comments := strings.NewReplacer("Read the file", "READ THE FILE")
+
+	tests := []struct {
+		comments bool
+		want     string
+	}{
+		{false, markdown.Replace(data)},
+		{true, comments.Replace(markdown.Replace(data))},
+	}
+
+	for _, tt := range tests {
+		notebookComments = tt.comments
+		got, err := translateNotebook("demo.ipynb", []byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("translateNotebook() with comments %v = %s, want %s", tt.comments, got, tt.want)
+		}
+	}
+}
+
+func TestNotebookSourceEdit(t *testing.T) {
+	tests := []struct {
+		data string
+		text string
+		want string
+	}{
+		{`{"source": "a"}`, "b\nc", `{"source": "b\nc"}`},
+		{`{"source": ["a\n", "b"]}`, "x\ny\nz\n", `{"source": ["x\n", "y\n", "z\n"]}`},
+		{"{\n  \"source\": [\n    \"a\"\n  ]\n}", "x\ny", "{\n  \"source\": [\n    \"x\\n\",\n    \"y\"\n  ]\n}"},
+	}
+
+	for _, tt := range tests {
+		root, err := parseJSONTree([]byte(tt.data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		edit := notebookSourceEdit([]byte(tt.data), root.get("source"), tt.text)
+		if got := string(applyXMLEdits([]byte(tt.data), []xmlEdit{edit})); got != tt.want {
+			t.Errorf("notebookSourceEdit(%q, %q) = %q, want %q", tt.data, tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Paragraphs and headings hold the text of OpenDocument files; spans,
+	// links and fields inside them are inline
+	odfRoles = markupRoles{
+		block: func(name string) bool { return name == "text:p" || name == "text:h" },
+		seps:  map[string]string{"text:s": " ", "text:tab": " ", "text:line-break": "<br>"},
+		skip: map[string]bool{
+			"text:note-citation": true, "dc:creator": true, "dc:date": true, "meta:date-string": true,
+		},
+	}
+
+	// Language attributes of styles
+	reODFLanguage = regexp.MustCompile(`(\s(?:fo:language|fo:country|style:rfc-language-tag)\s*=\s*")([^"]*)"`)
+)
+
+// odfCmd represents the odf command
+var odfCmd = &cobra.Command{
+	Use:   "odf",
+	Short: "Translate OpenDocument text, spreadsheets and presentations (ODT, ODS, ODP)",
+	Long: `Translates OpenDocument files without external tools. The package is opened as
+a ZIP archive and the paragraphs of content.xml and of the headers and footers in
+styles.xml are translated; spans, links, fields and other inline markup keep
+their place. The language of the styles and the dc:language of meta.xml are set
+to the target language. All other parts are copied unchanged.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateODF)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(odfCmd)
+}
+
+// **************************************************************************
+// translateODF is the fileHandler of the odf command. The paragraphs of the
+// content and the styles are translated in one batch and the language
+// metadata of the package is updated.
+// --------------------------------------------------------------------------
+func translateODF(name string, data []byte) ([]byte, error) {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".odt", ".ott", ".ods", ".ots", ".odp", ".otp":
+	default:
+		return nil, fmt.Errorf("unsupported OpenDocument format: %v", ext)
+	}
+
+	entries, err := readZipEntries(data, func(name string) bool {
+		return name == "content.xml" || name == "styles.xml" || name == "meta.xml"
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := entries["content.xml"]; !ok {
+		return nil, fmt.Errorf("no content.xml found")
+	}
+
+	docs := make(map[string]*markupDoc, len(entries))
+	for _, name := range []string{"content.xml", "styles.xml"} {
+		if content, ok := entries[name]; ok {
+			doc, err := parseMarkup(content, odfRoles)
+			if err != nil {
+				return nil, fmt.Errorf("%v: %v", name, err)
+			}
+			docs[name] = doc
+		}
+	}
+
+	if err := translateRichParagraphs(richParagraphs(docs["content.xml"], docs["styles.xml"])); err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[string][]byte, len(entries))
+	for name, doc := range docs {
+		replacements[name] = setODFLanguage(doc.rewrite(), targetLang)
+	}
+	if content, ok := entries["meta.xml"]; ok {
+		doc, err := parseMarkup(content, markupRoles{block: func(string) bool { return false }})
+		if err != nil {
+			return nil, fmt.Errorf("meta.xml: %v", err)
+		}
+		replacements["meta.xml"] = doc.rewrite(doc.elementTextEdits("dc:language", targetLang)...)
+	}
+
+	return writeZip(data, replacements)
+}
+
+// setODFLanguage sets the language attributes of all styles to lang. Styles
+// marked as having no language (zxx, none) are left alone.
+func setODFLanguage(data []byte, lang string) []byte {
+	base, region := splitLanguageTag(lang)
+	if region == "" {
+		region = "none"
+	}
+
+	return reODFLanguage.ReplaceAllFunc(data, func(m []byte) []byte {
+		sub := reODFLanguage.FindSubmatch(m)
+		value := string(sub[2])
+		if value == "zxx" || value == "none" {
+			return m
+		}
+		switch {
+		case strings.Contains(string(sub[1]), "fo:language"):
+			value = base
+		case strings.Contains(string(sub[1]), "fo:country"):
+			value = region
+		default:
+			value = lang
+		}
+
+		return []byte(string(sub[1]) + value + `"`)
+	})
+}
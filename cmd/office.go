@@ -0,0 +1,314 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// Roles of elements in the paragraph model of Office XML parts
+const (
+	officeNone = iota
+	officePara
+	officeRun
+	officeProps
+	officeText
+	officeSkip
+)
+
+var (
+	// Element roles of WordprocessingML (w:), DrawingML (a:, used by slides
+	// and by text boxes in documents) and SpreadsheetML shared strings
+	wordRoles = map[string]int{
+		"w:p": officePara, "w:r": officeRun, "w:rPr": officeProps, "w:t": officeText,
+		"a:p": officePara, "a:r": officeRun, "a:rPr": officeProps, "a:t": officeText,
+	}
+	slideRoles = map[string]int{
+		"a:p": officePara, "a:r": officeRun, "a:rPr": officeProps, "a:t": officeText,
+	}
+	sheetRoles = map[string]int{
+		"si": officePara, "r": officeRun, "rPr": officeProps, "t": officeText, "rPh": officeSkip,
+	}
+
+	// Parts holding translatable text in each kind of package
+	reWordPart  = regexp.MustCompile(`^word/(document|header\d*|footer\d*|footnotes|endnotes|comments)\.xml$`)
+	reSlidePart = regexp.MustCompile(`^ppt/(slides/slide|notesSlides/notesSlide)\d+\.xml$`)
+	reSheetPart = regexp.MustCompile(`^xl/sharedStrings\.xml$`)
+)
+
+// officeCmd represents the office command
+var officeCmd = &cobra.Command{
+	Use:   "office",
+	Short: "Translate Word, PowerPoint and Excel files (DOCX, PPTX, XLSX)",
+	Long: `Translates Office Open XML documents without external tools. The package is
+opened as a ZIP archive and the text of word/document.xml (with headers, footers,
+footnotes and comments), the slides and speaker notes, or xl/sharedStrings.xml is
+translated. All other parts are copied unchanged.
+
+Each paragraph is translated as a whole even when its text is split into runs
+with different formatting; the runs are kept and the translated text is
+distributed back over them, so bold, italic, colours and other styles survive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateOffice)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(officeCmd)
+}
+
+// officeUnit is a text element (w:t, a:t or t) of a paragraph
+type officeUnit struct {
+	open  int    // Token index of the start element
+	close int    // Token index of the end element
+	text  string // Source text, replaced by the translation
+	run   int    // Number of the enclosing run, -1 outside runs
+	props string // Raw run properties
+}
+
+// officeParagraph is a paragraph of an Office XML part. Adjacent runs with
+// identical properties (split by spell checking or revision marks) form one
+// group; groups are the units of formatting kept during translation.
+type officeParagraph struct {
+	units  []officeUnit
+	groups [][]int
+
+	// Run state of the enclosing paragraph, restored after nested paragraphs
+	savedRun   int
+	savedProps string
+}
+
+// officePart is a parsed XML part of the package
+type officePart struct {
+	data  []byte
+	toks  []xmlToken
+	paras []*officeParagraph
+}
+
+// **************************************************************************
+// translateOffice is the fileHandler of the office command. The text of all
+// matching parts is collected first and translated in one batch; paragraphs
+// made of a single formatting group are sent as plain text and others as
+// HTML with one <span> per group, which lets the translation reorder the
+// formatted words.
+// --------------------------------------------------------------------------
+func translateOffice(name string, data []byte) ([]byte, error) {
+	var match *regexp.Regexp
+	var roles map[string]int
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".docx", ".docm", ".dotx":
+		match, roles = reWordPart, wordRoles
+	case ".pptx", ".pptm", ".potx":
+		match, roles = reSlidePart, slideRoles
+	case ".xlsx", ".xlsm", ".xltx":
+		match, roles = reSheetPart, sheetRoles
+	default:
+		return nil, fmt.Errorf("unsupported Office format: %v", ext)
+	}
+
+	entries, err := readZipEntries(data, match.MatchString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Process parts in a stable order
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make(map[string]*officePart, len(entries))
+	var paras []*officeParagraph
+	for _, name := range names {
+		part, err := parseOfficePart(entries[name], roles)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", name, err)
+		}
+		parts[name] = part
+		paras = append(paras, part.paras...)
+	}
+
+	if err := translateOfficeParagraphs(paras); err != nil {
+		return nil, err
+	}
+
+	replacements := make(map[string][]byte, len(parts))
+	for name, part := range parts {
+		replacements[name] = part.rewrite()
+	}
+
+	return writeZip(data, replacements)
+}
+
+// parseOfficePart collects the paragraphs of an XML part
+func parseOfficePart(data []byte, roles map[string]int) (*officePart, error) {
+	toks, err := scanXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	part := &officePart{data: data, toks: toks}
+	var stack []*officeParagraph
+	run, props, runs := -1, "", 0
+	for i := 0; i < len(toks); i++ {
+		switch tok := toks[i].tok.(type) {
+		case xml.StartElement:
+			switch roles[qualifiedName(tok.Name)] {
+			case officePara:
+				stack = append(stack, &officeParagraph{savedRun: run, savedProps: props})
+				run, props = -1, ""
+			case officeRun:
+				runs++
+				run, props = runs, ""
+			case officeProps:
+				j := xmlElementEnd(toks, i)
+				if run >= 0 {
+					props = string(data[toks[i].start:toks[j].end])
+				}
+				i = j
+			case officeText:
+				j := xmlElementEnd(toks, i)
+				if len(stack) > 0 {
+					p := stack[len(stack)-1]
+					p.units = append(p.units, officeUnit{open: i, close: j, text: xmlCharData(toks[i+1 : j]), run: run, props: props})
+				}
+				i = j
+			case officeSkip:
+				i = xmlElementEnd(toks, i)
+			}
+		case xml.EndElement:
+			switch roles[qualifiedName(tok.Name)] {
+			case officePara:
+				if len(stack) == 0 {
+					break
+				}
+				p := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				run, props = p.savedRun, p.savedProps
+				if len(p.units) > 0 {
+					p.groupRuns()
+					part.paras = append(part.paras, p)
+				}
+			case officeRun:
+				run, props = -1, ""
+			}
+		}
+	}
+
+	return part, nil
+}
+
+// groupRuns merges the units of adjacent runs with identical properties
+func (p *officeParagraph) groupRuns() {
+	for k, u := range p.units {
+		if k > 0 {
+			prev := p.units[k-1]
+			if u.run >= 0 && u.run == prev.run+1 && u.props == prev.props {
+				last := len(p.groups) - 1
+				p.groups[last] = append(p.groups[last], k)
+				continue
+			}
+		}
+		p.groups = append(p.groups, []int{k})
+	}
+}
+
+// groupText returns the source text of a group
+func (p *officeParagraph) groupText(g int) string {
+	var sb strings.Builder
+	for _, k := range p.groups[g] {
+		sb.WriteString(p.units[k].text)
+	}
+
+	return sb.String()
+}
+
+// setGroupText stores the translation of a group in its first unit
+func (p *officeParagraph) setGroupText(g int, text string) {
+	for n, k := range p.groups[g] {
+		if n == 0 {
+			p.units[k].text = text
+		} else {
+			p.units[k].text = ""
+		}
+	}
+}
+
+// translateOfficeParagraphs translates the paragraphs in place, one piece
+// per formatting group
+func translateOfficeParagraphs(paras []*officeParagraph) error {
+	rich := make([]*richParagraph, len(paras))
+	for i, p := range paras {
+		rich[i] = &richParagraph{pieces: make([]string, len(p.groups))}
+		for g := range p.groups {
+			rich[i].pieces[g] = p.groupText(g)
+		}
+	}
+
+	if err := translateRichParagraphs(rich); err != nil {
+		return err
+	}
+	for i, p := range paras {
+		for g, text := range rich[i].pieces {
+			p.setGroupText(g, text)
+		}
+	}
+
+	return nil
+}
+
+// rewrite returns the part with the text elements of its paragraphs replaced
+func (part *officePart) rewrite() []byte {
+	var edits []xmlEdit
+	for _, p := range part.paras {
+		for _, u := range p.units {
+			open, close := part.toks[u.open], part.toks[u.close]
+			tag := string(part.data[open.start:open.end])
+			name := qualifiedName(open.tok.(xml.StartElement).Name)
+			if strings.HasSuffix(tag, "/>") {
+				tag = strings.TrimSpace(strings.TrimSuffix(tag, "/>")) + ">"
+			}
+			// Word and Excel drop leading and trailing spaces unless told otherwise
+			if !strings.HasPrefix(name, "a:") && !strings.Contains(tag, "xml:space") && u.text != strings.TrimSpace(u.text) {
+				tag = strings.TrimSuffix(tag, ">") + ` xml:space="preserve">`
+			}
+			edits = append(edits, xmlEdit{
+				start: open.start,
+				end:   close.end,
+				text:  tag + escapeXMLText(u.text) + "</" + name + ">",
+			})
+		}
+	}
+
+	return applyXMLEdits(part.data, edits)
+}
+
+// qualifiedName returns a raw element name as written, e.g. "w:p"
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+
+	return name.Space + ":" + name.Local
+}
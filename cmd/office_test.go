@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTranslateOfficePart(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles map[string]int
+		data  string
+		want  string
+		sent  []string
+	}{
+		{
+			"runs with equal properties form one piece",
+			wordRoles,
+			`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>Press </w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t>Save</w:t></w:r>` +
+				`<w:r><w:t xml:space="preserve"> to go on</w:t></w:r></w:p><w:p><w:r><w:t>Plain &amp; simple</w:t></w:r></w:p>`,
+			`<w:p><w:r><w:rPr><w:b/></w:rPr><w:t>PRESS SAVE</w:t></w:r><w:r><w:rPr><w:b/></w:rPr><w:t></w:t></w:r>` +
+				`<w:r><w:t xml:space="preserve"> TO GO ON</w:t></w:r></w:p><w:p><w:r><w:t>PLAIN &amp; SIMPLE</w:t></w:r></w:p>`,
+			[]string{"Plain & simple", `<span class="r0">Press Save</span><span class="r1"> to go on</span>`},
+		},
+		{
+			"spaces are preserved in Word but not in DrawingML",
+			wordRoles,
+			`<w:p><w:r><w:t>Hi </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>there</w:t></w:r></w:p><a:p><a:r><a:t>Hi </a:t></a:r><a:r><a:rPr i="1"/><a:t>all</a:t></a:r></a:p>`,
+			`<w:p><w:r><w:t xml:space="preserve">HI </w:t></w:r><w:r><w:rPr><w:i/></w:rPr><w:t>THERE</w:t></w:r></w:p><a:p><a:r><a:t>HI </a:t></a:r><a:r><a:rPr i="1"/><a:t>ALL</a:t></a:r></a:p>`,
+			[]string{`<span class="r0">Hi </span><span class="r1">there</span>`, `<span class="r0">Hi </span><span class="r1">all</span>`},
+		},
+		{
+			"phonetic runs of shared strings are skipped",
+			sheetRoles,
+			`<sst><si><t>Total</t></si><si><r><t>Net</t></r><rPh><t>ネット</t></rPh></si></sst>`,
+			`<sst><si><t>TOTAL</t></si><si><r><t>NET</t></r><rPh><t>ネット</t></rPh></si></sst>`,
+			[]string{"Total", "Net"},
+		},
+	}
+
+	for _, tt := range tests {
+		sent := fakeTranslate(t, strings.ToUpper)
+		part, err := parseOfficePart([]byte(tt.data), tt.roles)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if err := translateOfficeParagraphs(part.paras); err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if got := string(part.rewrite()); got != tt.want {
+			t.Errorf("%v: rewrite() = %q, want %q", tt.name, got, tt.want)
+		}
+		if !slices.Equal(*sent, tt.sent) {
+			t.Errorf("%v: sent %q, want %q", tt.name, *sent, tt.sent)
+		}
+	}
+}
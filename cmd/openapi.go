@@ -0,0 +1,196 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// Keys whose string values are documentation
+	openAPITextKeys = []string{"summary", "description", "title"}
+	// Keys holding example data
+	openAPIExampleKeys = []string{"example", "examples"}
+	// Keys holding values that must match the API exactly
+	openAPIDataKeys = []string{"enum", "const", "default", "$ref", "operationId", "pattern", "format"}
+
+	// Keys whose children are named by the author rather than keywords
+	openAPINameParents = []string{"properties", "patternProperties", "definitions", "$defs", "responses", "paths", "callbacks"}
+
+	// Markdown code spans and link targets, URLs and path parameters
+	reOpenAPIPlaceholder = regexp.MustCompile("`[^`]*`|\\]\\([^)]*\\)|https?://[^\\s)]+|\\{[^{}\\s]+\\}")
+)
+
+// openapiCmd represents the openapi command
+var openapiCmd = &cobra.Command{
+	Use:   "openapi",
+	Short: "Translate descriptions of OpenAPI and JSON Schema documents",
+	Long: `Translates the documentation of OpenAPI 3 (and Swagger 2) specifications and
+JSON Schema documents in YAML or JSON: the values of summary, description and
+title fields, and string examples made of words. Keys, paths, enums, constants,
+defaults, patterns and $refs are never changed. Markdown code spans, link
+targets, URLs and {parameters} inside descriptions are kept.
+
+JSON documents are rewritten in place, so their formatting is kept; YAML
+documents are re-encoded with the indentation of the input.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateOpenAPI)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(openapiCmd)
+}
+
+// **************************************************************************
+// translateOpenAPI is the fileHandler of the openapi command. JSON is chosen
+// by the .json extension or, for other extensions, by a document starting
+// with "{"; everything else is read as YAML.
+// --------------------------------------------------------------------------
+func translateOpenAPI(name string, data []byte) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(name))
+	if ext == ".json" || ext != ".yaml" && ext != ".yml" && bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		return translateOpenAPIJSON(data)
+	}
+
+	return translateOpenAPIYAML(data)
+}
+
+func translateOpenAPIJSON(data []byte) ([]byte, error) {
+	root, err := parseJSONTree(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var edits []xmlEdit
+	var segs []segment
+	walkJSONStrings(root, nil, func(path []string, node *jsonNode) {
+		if selectOpenAPIString(path, node.str) {
+			edits = append(edits, xmlEdit{start: node.start, end: node.end})
+			segs = append(segs, segment{Text: node.str})
+		}
+	})
+
+	strOut, err := translateSegments(segs, regexpProtector(reOpenAPIPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, text := range strOut {
+		var buf bytes.Buffer
+		writeJSONString(&buf, text)
+		edits[i].text = buf.String()
+	}
+
+	return applyXMLEdits(data, edits), nil
+}
+
+func translateOpenAPIYAML(data []byte) ([]byte, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := dec.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	var nodes []*yaml.Node
+	var segs []segment
+	for _, doc := range docs {
+		walkYAMLStrings(doc, nil, func(path []string, node *yaml.Node) {
+			if selectOpenAPIString(path, node.Value) {
+				nodes = append(nodes, node)
+				segs = append(segs, segment{Text: node.Value})
+			}
+		})
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(reOpenAPIPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		node.Value = strOut[i]
+	}
+
+	return encodeYAML(docs, detectYAMLIndent(data))
+}
+
+// **************************************************************************
+// selectOpenAPIString reports whether the string at the key path is
+// documentation. Strings below enum, const, default and similar keys are
+// data and never selected. Strings below example or examples are selected
+// when they look like prose, i.e. contain a letter and a space, so that
+// identifiers, dates and e-mail addresses in examples stay as they are.
+// --------------------------------------------------------------------------
+func selectOpenAPIString(path []string, value string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	example := false
+	for i, key := range path {
+		if isOpenAPIName(path, i) {
+			continue
+		}
+		switch {
+		case slices.Contains(openAPIDataKeys, key):
+			return false
+		case slices.Contains(openAPIExampleKeys, key):
+			example = true
+		}
+	}
+
+	last := path[len(path)-1]
+	switch {
+	case isOpenAPIName(path, len(path)-1):
+		return false
+	case len(path) > 2 && path[len(path)-3] == "examples" && last != "value":
+		// Summary and description of an Example Object
+		return slices.Contains(openAPITextKeys, last)
+	case example:
+		return hasLetters(value) && strings.ContainsAny(strings.TrimSpace(value), " \n")
+	default:
+		return slices.Contains(openAPITextKeys, last)
+	}
+}
+
+// isOpenAPIName reports whether path[i] is a name chosen by the author, like a
+// property name, a response code or the name of a component
+func isOpenAPIName(path []string, i int) bool {
+	switch {
+	case i > 0 && slices.Contains(openAPINameParents, path[i-1]):
+		return true
+	case i > 1 && path[i-2] == "components":
+		return true
+	}
+
+	return false
+}
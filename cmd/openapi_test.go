@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSelectOpenAPIString(t *testing.T) {
+	tests := []struct {
+		path  string
+		value string
+		want  bool
+	}{
+		{"info.title", "Pet Store", true},
+		{"info.version", "1.0.0", false},
+		{"paths./pets.get.summary", "List pets", true},
+		{"paths./pets.get.operationId", "listPets", false},
+		{"paths./pets.get.responses.200.description", "A list of pets", true},
+		{"components.schemas.description", "A schema named description", false},
+		{"components.schemas.Pet.properties.title.description", "The title", true},
+		{"components.schemas.Pet.properties.title.default", "Mr", false},
+		{"components.schemas.Pet.properties.kind.enum.0", "small dog", false},
+		{"components.schemas.Pet.$ref", "#/components/schemas/Animal", false},
+		{"components.schemas.Pet.example.name", "Rex the dog", true},
+		{"components.schemas.Pet.example.id", "pet-42", false},
+		{"components.schemas.Pet.example.email", "rex@example.com", false},
+		{"paths./pets.get.responses.200.content.application/json.examples.cat.summary", "A cat", true},
+		{"paths./pets.get.responses.200.content.application/json.examples.cat.externalValue", "https://example.com/cat", false},
+		{"paths./pets.get.responses.200.content.application/json.examples.cat.value", "Tom the cat", true},
+	}
+
+	for _, tt := range tests {
+		if got := selectOpenAPIString(strings.Split(tt.path, "."), tt.value); got != tt.want {
+			t.Errorf("selectOpenAPIString(%v, %q) = %v, want %v", tt.path, tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateOpenAPI(t *testing.T) {
+	fakeTranslate(t, strings.ToUpper)
+
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{
+			"api.json",
+			`{"info": {"title": "Pets", "version": "1.0"},` + "\n" +
+				` "paths": {"/pets/{id}": {"get": {"summary": "Get pet {id} via ` + "`GET`" + `", "operationId": "getPet"}}}}`,
+			`{"info": {"title": "PETS", "version": "1.0"},` + "\n" +
+				` "paths": {"/pets/{id}": {"get": {"summary": "GET PET {id} VIA ` + "`GET`" + `", "operationId": "getPet"}}}}`,
+		},
+		{
+			"api.yaml",
+			"openapi: 3.0.0\ninfo:\n    title: Pets\ncomponents:\n    schemas:\n        Pet:\n" +
+				"            description: See [docs](https://example.com/docs).\n            enum: [big dog, small dog]\n",
+			"openapi: 3.0.0\ninfo:\n    title: PETS\ncomponents:\n    schemas:\n        Pet:\n" +
+				"            description: SEE [DOCS](https://example.com/docs).\n            enum: [big dog, small dog]\n",
+		},
+	}
+
+	for _, tt := range tests {
+		got, err := translateOpenAPI(tt.name, []byte(tt.data))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("translateOpenAPI(%v) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
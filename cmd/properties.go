@@ -0,0 +1,359 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// Output encodings of .properties files
+const (
+	propertiesAuto   = "auto"
+	propertiesLatin1 = "iso-8859-1"
+	propertiesUTF8   = "utf-8"
+)
+
+// A MessageFormat argument such as {0} or {1,number}
+var reMessageFormatArg = regexp.MustCompile(`\{\s*\d+\s*[,}]`)
+
+// propertiesCmd represents the properties command
+var propertiesCmd = &cobra.Command{
+	Use:   "properties",
+	Short: "Translate Java .properties message bundles",
+	Long: `Translates the values of Java .properties files. Keys, comments and blank lines
+are kept, \uXXXX escapes and line continuations are understood, and MessageFormat
+arguments such as {0} or {1,number,#} are never sent for translation.
+
+The output encoding is chosen with --properties-encoding:
+  iso-8859-1  classic ResourceBundle encoding, non-ASCII characters are written as \uXXXX
+  utf-8       Java 9+ UTF-8 bundles, characters are written as is
+  auto        utf-8 if the input is UTF-8 with non-ASCII characters, iso-8859-1 otherwise
+
+Files in another encoding can be read with --input-encoding; they count as
+non-UTF-8 input for auto, and keys and comments keep the input encoding unless
+the output is utf-8.
+
+When --output is an existing directory the bundle name gets the target language
+suffix, e.g. messages.properties becomes messages_uk.properties.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isDir(outputFile) {
+			outputFile = filepath.Join(outputFile, localizedBundleName(filepath.Base(inputFile), targetLang))
+		}
+
+		if propertiesEncoding == propertiesUTF8 {
+			outputEncoding = propertiesUTF8
+		}
+
+		return runFileHandler(translateProperties)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(propertiesCmd)
+
+	propertiesCmd.Flags().StringVarP(&propertiesEncoding, "properties-encoding", "", propertiesAuto, "Output encoding: auto, iso-8859-1 or utf-8")
+	addInputEncodingFlag(propertiesCmd)
+}
+
+// propertiesEntry is a key/value pair of a .properties file. start and end
+// are the byte range of the raw value, which may span continuation lines.
+type propertiesEntry struct {
+	key   string
+	value string
+	start int
+	end   int
+}
+
+// **************************************************************************
+// translateProperties is the fileHandler of the properties command. Files
+// that are not valid UTF-8 are read as ISO-8859-1; like them, files converted
+// from --input-encoding are written escaped to ASCII in auto mode. Only the
+// value ranges are rewritten, escaped for the selected output encoding, so
+// the rest of the file is preserved.
+// --------------------------------------------------------------------------
+func translateProperties(name string, data []byte) ([]byte, error) {
+	legacy := inputCharset != nil // Converted from --input-encoding
+	latin1 := !utf8.Valid(data)
+	if latin1 {
+		decoded, err := charmap.ISO8859_1.NewDecoder().Bytes(data)
+		if err != nil {
+			return nil, err
+		}
+		data = decoded
+	}
+
+	ascii := false
+	switch propertiesEncoding {
+	case propertiesLatin1:
+		ascii = true
+	case propertiesUTF8:
+	case propertiesAuto:
+		ascii = legacy || latin1 || !hasNonASCII(data)
+	default:
+		return nil, fmt.Errorf("unknown properties encoding: %v", propertiesEncoding)
+	}
+
+	entries := parseProperties(data)
+	segs := make([]segment, len(entries))
+	doubled := make([]bool, len(entries))
+	for i, e := range entries {
+		segs[i] = segment{Text: e.value, Context: e.key}
+		// Apostrophes of MessageFormat patterns are written as ''
+		if reMessageFormatArg.MatchString(e.value) && !strings.Contains(strings.ReplaceAll(e.value, "''", ""), "'") {
+			doubled[i] = true
+			segs[i].Text = strings.ReplaceAll(e.value, "''", "'")
+		}
+	}
+
+	strOut, err := translateSegments(segs, icuProtector)
+	if err != nil {
+		return nil, err
+	}
+
+	edits := make([]xmlEdit, len(entries))
+	for i, e := range entries {
+		text := strOut[i]
+		if doubled[i] {
+			text = strings.ReplaceAll(text, "'", "''")
+		}
+		edits[i] = xmlEdit{start: e.start, end: e.end, text: escapePropertiesValue(text, ascii)}
+	}
+	out := applyXMLEdits(data, edits)
+
+	if latin1 && ascii {
+		return charmap.ISO8859_1.NewEncoder().Bytes(out)
+	}
+	return out, nil
+}
+
+// **************************************************************************
+// parseProperties splits a .properties file into key/value entries following
+// the rules of java.util.Properties.load: comment lines start with # or !,
+// a backslash at the end of a line continues the entry on the next line and
+// the key ends at the first unescaped '=', ':' or whitespace.
+// --------------------------------------------------------------------------
+func parseProperties(data []byte) []propertiesEntry {
+	var entries []propertiesEntry
+	n := len(data)
+	for pos := 0; pos < n; {
+		i := pos
+		for i < n && isPropertiesSpace(data[i]) {
+			i++
+		}
+		if i >= n || data[i] == '\n' || data[i] == '\r' || data[i] == '#' || data[i] == '!' {
+			pos = nextLine(data, i)
+			continue
+		}
+
+		// Join continuation lines, remembering where each byte came from
+		var logical []byte
+		var offsets []int
+		for i < n && data[i] != '\n' && data[i] != '\r' {
+			if data[i] == '\\' && i+1 < n {
+				if data[i+1] == '\n' || data[i+1] == '\r' {
+					i = nextLine(data, i+1)
+					for i < n && isPropertiesSpace(data[i]) {
+						i++
+					}
+					continue
+				}
+				logical = append(logical, data[i], data[i+1])
+				offsets = append(offsets, i, i+1)
+				i += 2
+				continue
+			}
+			logical = append(logical, data[i])
+			offsets = append(offsets, i)
+			i++
+		}
+		end := i
+		pos = nextLine(data, i)
+
+		k := 0
+		for k < len(logical) {
+			c := logical[k]
+			if c == '\\' {
+				k += 2
+				continue
+			}
+			if c == '=' || c == ':' || isPropertiesSpace(c) {
+				break
+			}
+			k++
+		}
+		k = min(k, len(logical))
+
+		v := k
+		for v < len(logical) && isPropertiesSpace(logical[v]) {
+			v++
+		}
+		if v < len(logical) && (logical[v] == '=' || logical[v] == ':') {
+			v++
+			for v < len(logical) && isPropertiesSpace(logical[v]) {
+				v++
+			}
+		}
+
+		start := end
+		if v < len(offsets) {
+			start = offsets[v]
+		}
+		entries = append(entries, propertiesEntry{
+			key:   unescapeProperties(logical[:k]),
+			value: unescapeProperties(logical[v:]),
+			start: start,
+			end:   end,
+		})
+	}
+
+	return entries
+}
+
+// nextLine returns the offset following the line terminator at or after i
+func nextLine(data []byte, i int) int {
+	for i < len(data) && data[i] != '\n' && data[i] != '\r' {
+		i++
+	}
+	if i+1 < len(data) && data[i] == '\r' && data[i+1] == '\n' {
+		return i + 2
+	}
+
+	return min(i+1, len(data))
+}
+
+func isPropertiesSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\f'
+}
+
+// unescapeProperties resolves \uXXXX, \t, \n, \r, \f and \x escapes
+func unescapeProperties(raw []byte) string {
+	var sb strings.Builder
+	s := string(raw)
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 >= len(s) {
+			sb.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			sb.WriteByte('\t')
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 'f':
+			sb.WriteByte('\f')
+		case 'u':
+			if i+4 < len(s) {
+				if r, err := strconv.ParseUint(s[i+1:i+5], 16, 16); err == nil {
+					// Combine surrogate pairs written as two escapes
+					if utf16.IsSurrogate(rune(r)) && i+10 < len(s) && s[i+5:i+7] == `\u` {
+						if r2, err := strconv.ParseUint(s[i+7:i+11], 16, 16); err == nil {
+							sb.WriteRune(utf16.DecodeRune(rune(r), rune(r2)))
+							i += 10
+							continue
+						}
+					}
+					sb.WriteRune(rune(r))
+					i += 4
+					continue
+				}
+			}
+			sb.WriteByte('u')
+		default:
+			sb.WriteByte(s[i])
+		}
+	}
+
+	return sb.String()
+}
+
+// escapePropertiesValue encodes a value for a .properties file. With ascii
+// set every character outside printable ASCII is written as \uXXXX.
+func escapePropertiesValue(s string, ascii bool) string {
+	var sb strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\f':
+			sb.WriteString(`\f`)
+		case r == ' ' && i == 0:
+			// Leading whitespace would be dropped by the parser
+			sb.WriteString(`\ `)
+		case ascii && (r < 0x20 || r > 0x7e):
+			for _, u := range utf16.Encode([]rune{r}) {
+				fmt.Fprintf(&sb, `\u%04X`, u)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+
+	return sb.String()
+}
+
+func hasNonASCII(data []byte) bool {
+	for _, c := range data {
+		if c >= utf8.RuneSelf {
+			return true
+		}
+	}
+
+	return false
+}
+
+// localizedBundleName adds a language suffix to a resource bundle file name,
+// replacing an existing one: messages_en.properties -> messages_uk.properties
+func localizedBundleName(base, lang string) string {
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	if m := reBundleLocale.FindStringSubmatchIndex(name); m != nil && isLanguageCode(name[m[2]:m[3]]) {
+		name = name[:m[0]]
+	}
+
+	return name + "_" + strings.ReplaceAll(lang, "-", "_") + ext
+}
+
+var reBundleLocale = regexp.MustCompile(`_([a-z]{2,3})(_[A-Z]{2})?$`)
+
+// isLanguageCode tells whether a code such as uk, pt_BR or en-US is a
+// language with locale data, so that the suffix of labels_new.properties is
+// not taken for Newari
+func isLanguageCode(code string) bool {
+	code = strings.ReplaceAll(code, "_", "-")
+	tag, err := language.Parse(code)
+
+	return err == nil && strings.EqualFold(tag.String(), code) && display.Self.Name(tag) != ""
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import "testing"
+
+func TestLocalizedBundleName(t *testing.T) {
+	tests := []struct {
+		base string
+		lang string
+		want string
+	}{
+		{"messages.properties", "uk", "messages_uk.properties"},
+		{"messages_en.properties", "uk", "messages_uk.properties"},
+		{"messages_en_US.properties", "pt-BR", "messages_pt_BR.properties"},
+		{"messages_fil.properties", "uk", "messages_uk.properties"},
+		{"labels_new.properties", "uk", "labels_new_uk.properties"},
+		{"labels_old.properties", "uk", "labels_old_uk.properties"},
+		{"app_min.properties", "de", "app_min_de.properties"},
+		{"app_lit.properties", "de", "app_lit_de.properties"},
+		{"ui_web.toml", "de", "ui_web_de.toml"},
+		{"strings_en_GB.toml", "de", "strings_de.toml"},
+	}
+
+	for _, tt := range tests {
+		if got := localizedBundleName(tt.base, tt.lang); got != tt.want {
+			t.Errorf("localizedBundleName(%q, %q) = %q, want %q", tt.base, tt.lang, got, tt.want)
+		}
+	}
+}
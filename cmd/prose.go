@@ -0,0 +1,147 @@
+/*
+This file implements the line-based model shared by the lightweight markup
+commands (AsciiDoc, reStructuredText, LaTeX) and the Markdown and code cells of
+notebooks. A format-specific scanner picks the paragraphs of prose out of the
+lines of a document, skipping directives, code and math; the paragraphs are
+translated with the inline markup of the format protected, re-wrapped to the
+width of the original lines and put back in place. All other lines are copied
+unchanged.
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// proseParagraph is a paragraph of prose spanning lines first to last
+type proseParagraph struct {
+	first  int
+	last   int
+	prefix string   // Kept start of the first line: indentation, list marker, heading marker
+	indent string   // Kept start of the following lines
+	suffix string   // Kept end of the last line, e.g. a trailing comment
+	text   []string // Prose of every line
+	adorn  []int    // Lines underlining or overlining a title, resized to the translation
+}
+
+// proseScanner returns the paragraphs of prose of a document
+type proseScanner func(lines []string) []proseParagraph
+
+// add appends the prose of line i to the paragraph
+func (p *proseParagraph) add(i int, text string) {
+	if len(p.text) == 0 {
+		p.first = i
+	}
+	p.last = i
+	p.text = append(p.text, text)
+}
+
+// proseDoc is a document split into lines, with the paragraphs found by a
+// scanner
+type proseDoc struct {
+	lines []string
+	paras []proseParagraph
+}
+
+// newProseDoc splits text into lines and scans them
+func newProseDoc(text string, scan proseScanner) *proseDoc {
+	lines := strings.Split(text, "\n")
+	return &proseDoc{lines: lines, paras: scan(lines)}
+}
+
+// proseHandler returns the fileHandler of a markup format
+func proseHandler(scan proseScanner, protect *regexp.Regexp) fileHandler {
+	return func(name string, data []byte) ([]byte, error) {
+		return translateProse(data, scan, protect)
+	}
+}
+
+// **************************************************************************
+// translateProse translates the paragraphs found by scan. Line endings (LF
+// or CRLF) are kept.
+// --------------------------------------------------------------------------
+func translateProse(data []byte, scan proseScanner, protect *regexp.Regexp) ([]byte, error) {
+	text := string(data)
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+
+	doc := newProseDoc(text, scan)
+	if err := translateProseDocs([]*proseDoc{doc}, protect); err != nil {
+		return nil, err
+	}
+
+	return []byte(strings.Join(doc.lines, newline)), nil
+}
+
+// **************************************************************************
+// translateProseDocs translates the paragraphs of the documents in one batch
+// and replaces their lines. Paragraphs whose text outside of the protected
+// markup has no letters are left alone. Paragraphs of several lines are
+// wrapped to the width of their longest line; single lines stay single
+// lines.
+// --------------------------------------------------------------------------
+func translateProseDocs(docs []*proseDoc, protect *regexp.Regexp) error {
+	type job struct {
+		doc  *proseDoc
+		para proseParagraph
+	}
+
+	var jobs []job
+	var segs []segment
+	for _, doc := range docs {
+		for _, p := range doc.paras {
+			joined := strings.Join(p.text, " ")
+			if !hasLetters(protect.ReplaceAllString(joined, "")) {
+				continue
+			}
+			jobs = append(jobs, job{doc, p})
+			segs = append(segs, segment{Text: joined})
+		}
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(protect))
+	if err != nil {
+		return err
+	}
+
+	// Replace the paragraphs from the end so that line numbers stay valid
+	for i := len(jobs) - 1; i >= 0; i-- {
+		doc, p := jobs[i].doc, jobs[i].para
+		wrapped := []string{strOut[i]}
+		if len(p.text) > 1 {
+			width := 0
+			for _, t := range p.text {
+				width = max(width, utf8.RuneCountInString(t))
+			}
+			wrapped = wrapWords(strings.Fields(strOut[i]), width, nil)
+		}
+
+		out := make([]string, len(wrapped))
+		for k, w := range wrapped {
+			if k == 0 {
+				out[k] = p.prefix + w
+			} else {
+				out[k] = p.indent + w
+			}
+		}
+		out[len(out)-1] += p.suffix
+
+		for _, a := range p.adorn {
+			width := utf8.RuneCountInString(strings.TrimRight(out[0], " "))
+			doc.lines[a] = strings.Repeat(doc.lines[a][:1], max(width, 2))
+		}
+		doc.lines = append(doc.lines[:p.first], append(out, doc.lines[p.last+1:]...)...)
+	}
+
+	return nil
+}
+
+// leadingSpace returns the indentation of a line
+func leadingSpace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+// proseTexts returns the prose found by scan in doc, one string per
+// paragraph with its lines joined by |
+func proseTexts(scan proseScanner, doc string) []string {
+	var texts []string
+	for _, p := range scan(strings.Split(doc, "\n")) {
+		texts = append(texts, strings.Join(p.text, "|"))
+	}
+
+	return texts
+}
+
+func TestScanRST(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{"Title\n=====\n\nOne line\nand another.\n", []string{"Title", "One line|and another."}},
+		{"Example::\n\n    code()\n\nAfter.\n", []string{"Example", "After."}},
+		{"Example:\n\n::\n\n    code()\n    more()\n\nAfter.\n", []string{"Example:", "After."}},
+		{"::\n\n  code()\n", nil},
+		{"- First item\n  goes on.\n- Second\n", []string{"First item|goes on.", "Second"}},
+		{".. note:: Read this.\n\n.. code-block:: go\n\n   x := 1\n\nDone.\n", []string{"Read this.", "Done."}},
+		{".. comment\n   hidden\n\nShown.\n", []string{"Shown."}},
+		{"+---+---+\n| a | b |\n+---+---+\n\nText.\n", []string{"Text."}},
+		{"----\n\nAfter a transition.\n", []string{"After a transition."}},
+	}
+
+	for _, tt := range tests {
+		if got := proseTexts(scanRST, tt.doc); !slices.Equal(got, tt.want) {
+			t.Errorf("scanRST(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestScanAsciiDoc(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{"= Title\n\nOne line\nand another.\n", []string{"Title", "One line|and another."}},
+		{"----\ncode()\n----\nAfter.\n", []string{"After."}},
+		{"```go\ncode()\n```\n", nil},
+		{"  literal\n  lines\n\nProse.\n", []string{"Prose."}},
+		{"* First\n* Second\n", []string{"First", "Second"}},
+		{".A caption\nText.\n", []string{"A caption", "Text."}},
+		{"|===\n| Cell one | Cell two\n|===\n", []string{"| Cell one | Cell two"}},
+	}
+
+	for _, tt := range tests {
+		if got := proseTexts(scanAsciiDoc, tt.doc); !slices.Equal(got, tt.want) {
+			t.Errorf("scanAsciiDoc(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
+
+func TestScanLaTeX(t *testing.T) {
+	tests := []struct {
+		doc  string
+		want []string
+	}{
+		{"\\documentclass{article}\n\\title{Kept}\n\\begin{document}\nOne line\nand \\emph{another}.\n\\end{document}\nAfter.\n",
+			[]string{"One line|and \\emph{another}."}},
+		{"\\section{Intro}\nText % note\nmore.\n", []string{"\\section{Intro}", "Text", "more."}},
+		{"\\begin{verbatim}\ncode\n\\end{verbatim}\nAfter.\n", []string{"After."}},
+		{"Before.\n\\[\nx^2\n\\]\nAfter.\n", []string{"Before.", "After."}},
+		{"\\begin{itemize}\n\\item First\n\\item Second\n\\end{itemize}\n", []string{"First", "Second"}},
+		{"\\begin{tabular}{ll}\nOne & Two \\\\\nThree & Four \\\\\n\\end{tabular}\n", []string{"One & Two \\\\", "Three & Four \\\\"}},
+	}
+
+	for _, tt := range tests {
+		if got := proseTexts(scanLaTeX, tt.doc); !slices.Equal(got, tt.want) {
+			t.Errorf("scanLaTeX(%q) = %q, want %q", tt.doc, got, tt.want)
+		}
+	}
+}
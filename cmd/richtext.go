@@ -0,0 +1,118 @@
+/*
+This file implements the translation of paragraphs whose text is interrupted
+by markup that has to stay in place: formatting runs of Office documents,
+inline elements of XHTML, spans of OpenDocument text. Such a paragraph is
+sent to the API as HTML with one marked <span> per piece of text, so that it
+is translated as a whole, and the translated text is then distributed back
+over the pieces.
+*/
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// Piece markers of paragraphs sent as HTML
+	reRunSpan = regexp.MustCompile(`(?s)<span class="r(\d+)">(.*?)</span>`)
+	reAnyTag  = regexp.MustCompile(`<[^<>]*>`)
+)
+
+// richParagraph is a paragraph split into pieces by markup
+type richParagraph struct {
+	pieces []string // Text of every piece; replaced by the translation
+	seps   []string // HTML standing for the markup after each piece, if any
+}
+
+// **************************************************************************
+// translateRichParagraphs translates the paragraphs in place. A paragraph
+// with a single non-empty piece is translated as plain text. Others are sent
+// as HTML, e.g.
+//
+//	<span class="r0">Press </span><span class="r1">Save</span><span class="r2"> to go on</span>
+//
+// Text the translation puts between the spans is appended to the preceding
+// piece, unless the markup there is represented by a separator (a line break
+// or a space element), which already stays in the document.
+// --------------------------------------------------------------------------
+func translateRichParagraphs(paras []*richParagraph) error {
+	var plain []*richParagraph
+	var plainIdx []int
+	var plainSegs []segment
+	var rich []*richParagraph
+	var richTexts []string
+	for _, p := range paras {
+		if !hasLetters(strings.Join(p.pieces, "")) {
+			continue
+		}
+
+		nonEmpty, last := 0, 0
+		for k, piece := range p.pieces {
+			if strings.TrimSpace(piece) != "" {
+				nonEmpty, last = nonEmpty+1, k
+			}
+		}
+		if nonEmpty == 1 {
+			plain = append(plain, p)
+			plainIdx = append(plainIdx, last)
+			plainSegs = append(plainSegs, segment{Text: p.pieces[last]})
+			continue
+		}
+
+		var sb strings.Builder
+		for k, piece := range p.pieces {
+			fmt.Fprintf(&sb, `<span class="r%d">%s</span>`, k, html.EscapeString(piece))
+			if k < len(p.seps) {
+				sb.WriteString(p.seps[k])
+			}
+		}
+		rich = append(rich, p)
+		richTexts = append(richTexts, sb.String())
+	}
+
+	strOut, err := translateSegments(plainSegs, nil)
+	if err != nil {
+		return err
+	}
+	for i, p := range plain {
+		p.pieces[plainIdx[i]] = strOut[i]
+	}
+
+	htmlOut, err := translateBatched(richTexts, formatHTML)
+	if err != nil {
+		return err
+	}
+	for i, p := range rich {
+		p.distribute(htmlOut[i])
+	}
+
+	return nil
+}
+
+// distribute assigns a translated HTML paragraph back to the pieces
+func (p *richParagraph) distribute(text string) {
+	pieces := make([]string, len(p.pieces))
+	last, pos := 0, 0
+	between := func(s string) {
+		s = html.UnescapeString(reAnyTag.ReplaceAllString(reNoTranslateSpan.ReplaceAllString(s, ""), ""))
+		if strings.TrimSpace(s) == "" && last < len(p.seps) && p.seps[last] != "" {
+			return
+		}
+		pieces[last] += s
+	}
+	for _, loc := range reRunSpan.FindAllStringSubmatchIndex(text, -1) {
+		between(text[pos:loc[0]])
+		if k, err := strconv.Atoi(text[loc[2]:loc[3]]); err == nil && k < len(pieces) {
+			last = k
+		}
+		pieces[last] += html.UnescapeString(text[loc[4]:loc[5]])
+		pos = loc[1]
+	}
+	between(text[pos:])
+
+	p.pieces = pieces
+}
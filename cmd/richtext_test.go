@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRichParagraphDistribute(t *testing.T) {
+	tests := []struct {
+		seps []string
+		text string
+		want []string
+	}{
+		{[]string{"", "", ""}, `<span class="r0">Натисніть </span><span class="r1">Зберегти</span><span class="r2">, щоб продовжити</span>`,
+			[]string{"Натисніть ", "Зберегти", ", щоб продовжити"}},
+		{[]string{"", ""}, `<span class="r1">Speichern</span> <span class="r0">drücken</span>`, []string{"drücken", "Speichern "}},
+		{[]string{"", ""}, `Bitte <span class="r0">Tom &amp; Jerry</span> sehen<span class="r1">.</span>`, []string{"Bitte Tom & Jerry sehen", "."}},
+		{[]string{"<br>", ""}, `<span class="r0">Eins</span><br><span class="r1">Zwei</span>`, []string{"Eins", "Zwei"}},
+		{[]string{`<span translate="no">ls</span>`, ""}, `<span class="r0">Führen Sie </span><span translate="no">ls</span><span class="r1"> aus</span>`,
+			[]string{"Führen Sie ", " aus"}},
+		{[]string{"", ""}, `<span class="r0">Alles</span> <b>in</b> einem`, []string{"Alles in einem", ""}},
+	}
+
+	for _, tt := range tests {
+		p := &richParagraph{pieces: make([]string, len(tt.seps)), seps: tt.seps}
+		p.distribute(tt.text)
+		if !slices.Equal(p.pieces, tt.want) {
+			t.Errorf("distribute(%q) = %q, want %q", tt.text, p.pieces, tt.want)
+		}
+	}
+}
+
+func TestTranslateRichParagraphs(t *testing.T) {
+	sent := fakeTranslate(t, strings.ToUpper)
+
+	paras := []*richParagraph{
+		{pieces: []string{"Press ", "Save", " now"}, seps: []string{"", "", ""}},
+		{pieces: []string{"", "Only one", " "}, seps: []string{"", "", ""}},
+		{pieces: []string{"42", " %"}, seps: []string{"", ""}},
+	}
+	if err := translateRichParagraphs(paras); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]string{{"PRESS ", "SAVE", " NOW"}, {"", "ONLY ONE", " "}, {"42", " %"}}
+	for i, p := range paras {
+		if !slices.Equal(p.pieces, want[i]) {
+			t.Errorf("paragraph %d = %q, want %q", i, p.pieces, want[i])
+		}
+	}
+	if len(*sent) != 2 {
+		t.Errorf("sent %q, want one plain and one HTML paragraph", *sent)
+	}
+}
+
+func TestParseMarkup(t *testing.T) {
+	sent := fakeTranslate(t, strings.ToUpper)
+
+	data := `<html lang="en"><body><p>Run <code>ls</code> to<br/>list <b>all</b> files</p><pre>kept as is</pre><div>Box<p>Inner</p></div></body></html>`
+	doc, err := parseMarkup([]byte(data), xhtmlRoles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := translateRichParagraphs(richParagraphs(doc)); err != nil {
+		t.Fatal(err)
+	}
+
+	got := string(doc.rewrite(doc.rootLangEdit("uk")...))
+	want := `<html lang="uk"><body><p>RUN <code>ls</code> TO<br/>LIST <b>ALL</b> FILES</p><pre>kept as is</pre><div>BOX<p>INNER</p></div></body></html>`
+	if got != want {
+		t.Errorf("rewrite() = %q, want %q", got, want)
+	}
+	if !slices.Contains(*sent, `<span class="r0">Run </span><span translate="no">ls</span><span class="r1"> to</span><br><span class="r2">list </span><span class="r3">all</span><span class="r4"> files</span>`) {
+		t.Errorf("sent %q, want the paragraph with its code and line break", *sent)
+	}
+}
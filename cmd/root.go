@@ -37,7 +37,43 @@ var (
 	csvColumn    []string // Column number to translate (for CSV files)
 	csvDelimiter string   // Delimiter for CSV files
 	csvComment   string   // Comment character for CSV files
+	csvHeader    bool     // Keep the first CSV row as a header naming the columns
 	version      bool     // Print version of the application
+	i18nFormat   string   // Format of JSON i18n resource files
+	yamlInclude  []string // Key path patterns to translate (for YAML files)
+	yamlExclude  []string // Key path patterns to skip (for YAML files)
+
+	propertiesEncoding string // Output encoding of .properties files
+	subtitleMaxChars   int    // Maximum characters per subtitle line
+	subtitleMaxLines   int    // Maximum lines per subtitle cue
+	subtitleMerge      bool   // Merge subtitle cues into sentences before translation
+
+	jsonSelect []string // Path expressions of the fields to translate (for JSON files)
+	jsonLines  bool     // Treat the input as JSON Lines
+	xmlXPath   []string // XPath selectors of the text to translate (for XML files)
+	goComments bool     // Translate comments of Go source files
+	goStrings  []string // Functions whose string literal arguments are translated
+
+	notebookComments bool // Translate comments of code cells of notebooks
+
+	csvNewColumns     string // Write translations to new columns: append or insert
+	csvColumnName     string // Template of the headers of new columns
+	csvSourceColumn   string // Column holding the source language of each row
+	csvTargetColumn   string // Column holding the target language of each row
+	csvDetectedColumn string // Header of the column receiving the source language of each row
+	csvAutoColumns    bool   // Translate only the columns that look like natural language
+	csvPreview        bool   // Print the columns to translate and exit
+	csvKeepComments   bool   // Keep comment and blank lines of CSV files
+	csvLazyQuotes     bool   // Accept bare quotes in CSV fields
+	csvVariableFields bool   // Accept CSV rows with different numbers of fields
+	csvWhere          string // Filter expression of the CSV cells to translate
+	csvContextColumn  string // Column giving the context of each CSV row
+
+	csvMaxLength    []string // Maximum lengths of the translations of CSV columns
+	csvLengthReport string   // CSV file reporting the translations that are too long
+
+	inputEncoding  string // Character encoding of input files, or "auto"
+	outputEncoding string // Character encoding of output files
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -112,6 +148,7 @@ func init() {
 	rootCmd.PersistentFlags().StringVarP(&credentials, "credentials", "c", "", "Path to Google Cloud credentials JSON file")
 	rootCmd.PersistentFlags().BoolVarP(&useAdvanced, "advanced", "a", false, "Use Advanced Google Translate API")
 	rootCmd.Flags().BoolVarP(&version, "version", "v", false, "Print the version of the application")
+	addEncodingFlags(rootCmd)
 
 	// Mark required flags
 	// These flags must be provided or the application will show an error
@@ -0,0 +1,198 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Inline markup of reStructuredText: roles, inline literals, link targets,
+	// the backquotes of interpreted text and hyperlinks, substitution, footnote
+	// and citation references, and standalone URLs
+	reRSTPlaceholder = regexp.MustCompile("(?::[\\w.+-]+)+:`[^`]*`|``[^`]*``|<[^<>\\s]+>`__?|`_{0,2}|\\|[^|\\s][^|]*\\|_{0,2}|\\[[#*\\w.-]*\\]_|https?://[^\\s<>`]+")
+
+	// Directives, with their indentation, name and argument
+	reRSTDirective = regexp.MustCompile(`^(\s*\.\.\s+([\w:.-]+)::\s*)(.*)$`)
+	// Footnotes and citations
+	reRSTFootnote = regexp.MustCompile(`^(\s*\.\.\s+\[[#*\w.-]*\]\s+)(.*)$`)
+	// Grid tables and simple tables
+	reRSTTable = regexp.MustCompile(`^(?:\+[-=+]+\+|=+(?:\s+=+)+)$`)
+	// Items of bullet and enumerated lists, and line blocks
+	reRSTItem = regexp.MustCompile(`^(\s*(?:[-*+•]|\d+[.)]|#\.|\(\w+\)|[a-zA-Z][.)]|\|)\s+)(.*)$`)
+	// Fields of field lists and directive options
+	reRSTField = regexp.MustCompile("^(\\s*:[^:`\\s][^:`]*:)(?:\\s+(.*))?$")
+
+	// Directives with prose content; true when the argument is prose as well
+	rstProseDirectives = map[string]bool{
+		"note": true, "warning": true, "tip": true, "important": true, "caution": true,
+		"danger": true, "attention": true, "hint": true, "error": true, "seealso": true,
+		"admonition": true, "topic": true, "sidebar": true, "rubric": true, "centered": true,
+		"versionadded": true, "versionchanged": true, "deprecated": true,
+		"epigraph": false, "highlights": false, "pull-quote": false, "compound": false,
+		"container": false, "only": false, "hlist": false, "glossary": false, "rst-class": false,
+	}
+)
+
+// rstCmd represents the rst command
+var rstCmd = &cobra.Command{
+	Use:   "rst",
+	Short: "Translate reStructuredText documents",
+	Long: `Translates the prose of reStructuredText documents (.rst), including Sphinx
+sources: paragraphs, section titles, list items, field bodies, footnotes and
+the content of admonitions like note and warning. Comments, targets,
+substitution definitions, tables, literal blocks and directives such as
+code-block, math, image or toctree are copied unchanged, and so are roles
+(:ref:, :class:), inline literals and link targets.
+
+Paragraphs spanning several lines are re-wrapped to the width of their longest
+line, and section title adornments are resized to the translated titles.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(proseHandler(scanRST, reRSTPlaceholder))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rstCmd)
+	addEncodingFlags(rstCmd)
+}
+
+// **************************************************************************
+// scanRST returns the paragraphs of a reStructuredText document. The blocks
+// indented below comments, literal markers (::) and directives other than
+// admonitions are skipped. A list item, field or footnote continues over the
+// lines indented past its first line; a plain paragraph over the lines with
+// the same indentation.
+// --------------------------------------------------------------------------
+func scanRST(lines []string) []proseParagraph {
+	var paras []proseParagraph
+	var cur *proseParagraph
+	base, hanging := 0, false // Indentation of the current paragraph and whether it is an item
+	skipAbove := -1           // Lines indented more than this are skipped
+	skipToBlank := false      // Whether a table is being skipped
+	options := false          // Whether directive options may follow
+
+	end := func() {
+		if cur == nil {
+			return
+		}
+		last := &cur.text[len(cur.text)-1]
+		if strings.HasSuffix(*last, "::") {
+			// The indented block following a literal marker is code
+			text := strings.TrimRight(strings.TrimSuffix(*last, "::"), " ")
+			cur.suffix = (*last)[len(text):]
+			*last = text
+			skipAbove = base
+			if hanging {
+				skipAbove = len(cur.prefix)
+			}
+		}
+		paras = append(paras, *cur)
+		cur = nil
+	}
+	start := func(i int, prefix, text string, item bool) {
+		end()
+		cur = &proseParagraph{prefix: prefix, indent: prefix}
+		if item {
+			cur.indent = strings.Repeat(" ", len(prefix))
+		}
+		cur.add(i, text)
+		base, hanging = len(leadingSpace(prefix)), item
+	}
+
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		width := len(leadingSpace(line))
+		switch {
+		case skipToBlank:
+			skipToBlank = trimmed != ""
+			continue
+		case skipAbove >= 0 && (trimmed == "" || width > skipAbove):
+			continue
+		case options && reRSTField.MatchString(line):
+			continue
+		}
+		skipAbove, options = -1, false
+
+		var m []string
+		switch {
+		case trimmed == "":
+			end()
+		case trimmed == "::":
+			// A bare literal marker: the indented block below is code
+			end()
+			skipAbove = width
+		case isRSTAdornment(trimmed):
+			if cur != nil && !hanging && len(cur.text) == 1 && cur.last == i-1 {
+				cur.adorn = append(cur.adorn, i)
+				if over := cur.first - 1; over >= 0 && strings.TrimSpace(lines[over]) == trimmed {
+					cur.adorn = append(cur.adorn, over)
+				}
+			}
+			end()
+		case reRSTTable.MatchString(trimmed):
+			end()
+			skipToBlank = true
+		default:
+			if m = reRSTDirective.FindStringSubmatch(line); m != nil {
+				end()
+				argProse, ok := rstProseDirectives[m[2]]
+				switch {
+				case !ok:
+					skipAbove = width
+				case argProse && m[3] != "":
+					start(i, m[1], m[3], true)
+					options = true
+				default:
+					options = true
+				}
+			} else if m = reRSTFootnote.FindStringSubmatch(line); m != nil {
+				start(i, m[1], m[2], true)
+			} else if strings.HasPrefix(trimmed, "..") && (trimmed == ".." || trimmed[2] == ' ') {
+				// Comments, targets and substitution definitions
+				end()
+				skipAbove = width
+			} else if m = reRSTItem.FindStringSubmatch(line); m != nil {
+				start(i, m[1], m[2], true)
+			} else if m = reRSTField.FindStringSubmatch(line); m != nil {
+				start(i, m[1]+" ", m[2], true)
+			} else if cur != nil && (hanging && width > base || !hanging && width == base) {
+				if hanging && len(cur.text) == 1 {
+					cur.indent = leadingSpace(line)
+				}
+				cur.add(i, trimmed)
+			} else {
+				start(i, leadingSpace(line), trimmed, false)
+			}
+		}
+	}
+	end()
+
+	return paras
+}
+
+// isRSTAdornment reports whether the line is a section adornment or a
+// transition: a repeated punctuation character
+func isRSTAdornment(line string) bool {
+	if len(line) < 2 || !strings.ContainsRune("=-`:'\"~^_*+#<>.!$%&(),/;?@[]\\{|}", rune(line[0])) {
+		return false
+	}
+
+	return strings.Count(line, line[:1]) == len(line)
+}
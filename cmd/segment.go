@@ -0,0 +1,263 @@
+/*
+This file provides the batching layer shared by the structured file handlers.
+A handler extracts the translatable strings of a document as segments,
+translates all of them at once with translateSegments and writes the results
+back into the document. Placeholders and other markup that must survive the
+round trip are described by a protector and are sent to the translation API
+as untranslatable HTML spans.
+*/
+package cmd
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Limits of a single translation request. The Basic API accepts at most 128
+// strings per call and both APIs reject very large payloads, so segments are
+// sent in batches that stay safely below those limits.
+const (
+	maxBatchItems = 100
+	maxBatchChars = 20000
+)
+
+// segment is a single translatable string extracted from a document
+type segment struct {
+	Text    string // Source text
+	Context string // Optional note for translators (e.g. ARB description)
+}
+
+// chunk is a part of a segment's text. Chunks with keep set are sent to the
+// translation API as untranslatable and come back unchanged.
+type chunk struct {
+	text string
+	keep bool
+}
+
+// protector splits a text into translatable and protected chunks
+type protector func(text string) []chunk
+
+var (
+	reNoTranslateSpan = regexp.MustCompile(`(?s)<span translate="no">(.*?)</span>`)
+	reLineBreak       = regexp.MustCompile(`(?i)<br\s*/?>`)
+)
+
+// **************************************************************************
+// translateSegments translates a list of segments and returns the translated
+// texts in the same order.
+//
+// Before anything is sent to the translation API the function:
+//  1. Passes through segments without any letters (numbers, bare placeholders)
+//  2. Strips leading and trailing whitespace and restores it afterwards
+//  3. Translates identical segments only once; the context takes part in the
+//     comparison, so equal strings with different meanings stay separate
+//  4. Splits the text with protect (if not nil) and marks the protected
+//     chunks as untranslatable
+//
+// Neither Google API accepts free-form context, so Context only affects the
+// grouping of identical strings.
+// --------------------------------------------------------------------------
+func translateSegments(segs []segment, protect protector) ([]string, error) {
+	type job struct {
+		body    string
+		html    bool
+		targets []int
+	}
+
+	strOut := make([]string, len(segs))
+	jobs := make([]*job, 0, len(segs))
+	seen := make(map[string]*job)
+	for i, seg := range segs {
+		strOut[i] = seg.Text
+
+		core := strings.TrimSpace(seg.Text)
+		if !hasLetters(core) {
+			continue
+		}
+
+		key := seg.Context + "\x00" + core
+		if j, ok := seen[key]; ok {
+			j.targets = append(j.targets, i)
+			continue
+		}
+
+		j := &job{targets: []int{i}}
+
+		chunks := []chunk{{text: core}}
+		if protect != nil {
+			chunks = protect(core)
+		}
+		j.body, j.html = encodeChunks(chunks)
+		if j.body == "" {
+			continue
+		}
+
+		seen[key] = j
+		jobs = append(jobs, j)
+	}
+
+	// Plain and HTML jobs are sent in separate requests
+	for _, asHTML := range []bool{false, true} {
+		var group []*job
+		var texts []string
+		for _, j := range jobs {
+			if j.html == asHTML {
+				group = append(group, j)
+				texts = append(texts, j.body)
+			}
+		}
+
+		format := formatText
+		if asHTML {
+			format = formatHTML
+		}
+		translated, err := translateBatched(texts, format)
+		if err != nil {
+			return nil, err
+		}
+
+		for k, j := range group {
+			text := translated[k]
+			if asHTML {
+				text = decodeChunks(text)
+			}
+			// Every segment keeps its own surrounding whitespace
+			for _, i := range j.targets {
+				lead := len(segs[i].Text) - len(strings.TrimLeftFunc(segs[i].Text, unicode.IsSpace))
+				trail := len(strings.TrimRightFunc(segs[i].Text, unicode.IsSpace))
+				strOut[i] = segs[i].Text[:lead] + text + segs[i].Text[trail:]
+			}
+		}
+	}
+
+	return strOut, nil
+}
+
+// **************************************************************************
+// translateBatched sends texts to the selected translation API in batches
+// limited by maxBatchItems and maxBatchChars, and returns the translations
+// in input order.
+// --------------------------------------------------------------------------
+func translateBatched(texts []string, format string) ([]string, error) {
+	strOut, _, err := translateBatchedDetect(texts, format)
+
+	return strOut, err
+}
+
+// translateBatchedDetect works like translateBatched and also returns the
+// source language of every text (see translateExDetect)
+func translateBatchedDetect(texts []string, format string) ([]string, []string, error) {
+	strOut := make([]string, 0, len(texts))
+	detected := make([]string, 0, len(texts))
+	for start := 0; start < len(texts); {
+		end, size := start, 0
+		for end < len(texts) && end-start < maxBatchItems {
+			if end > start && size+len(texts[end]) > maxBatchChars {
+				break
+			}
+			size += len(texts[end])
+			end++
+		}
+
+		translated, langs, err := translateExDetect(texts[start:end], useAdvanced, format)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(translated) != end-start {
+			return nil, nil, fmt.Errorf("expected %d translations, got %d", end-start, len(translated))
+		}
+
+		strOut = append(strOut, translated...)
+		detected = append(detected, langs...)
+		start = end
+	}
+
+	return strOut, detected, nil
+}
+
+// encodeChunks joins chunks into the text sent to the API. When any chunk is
+// protected the result is HTML and the second value is true. An empty string
+// is returned when there is nothing to translate.
+func encodeChunks(chunks []chunk) (string, bool) {
+	protected, translatable := false, false
+	for _, c := range chunks {
+		if c.keep {
+			protected = true
+		} else if hasLetters(c.text) {
+			translatable = true
+		}
+	}
+	if !translatable {
+		return "", false
+	}
+
+	var sb strings.Builder
+	for _, c := range chunks {
+		if !protected {
+			sb.WriteString(c.text)
+		} else if c.keep {
+			sb.WriteString(`<span translate="no">`)
+			sb.WriteString(html.EscapeString(c.text))
+			sb.WriteString(`</span>`)
+		} else {
+			sb.WriteString(strings.ReplaceAll(html.EscapeString(c.text), "\n", "<br>"))
+		}
+	}
+
+	return sb.String(), protected
+}
+
+// decodeChunks turns a translated HTML string produced from encodeChunks back
+// into plain text, restoring protected chunks verbatim
+func decodeChunks(text string) string {
+	var sb strings.Builder
+	pos := 0
+	for _, loc := range reNoTranslateSpan.FindAllStringSubmatchIndex(text, -1) {
+		sb.WriteString(decodeHTMLText(text[pos:loc[0]]))
+		sb.WriteString(html.UnescapeString(text[loc[2]:loc[3]]))
+		pos = loc[1]
+	}
+	sb.WriteString(decodeHTMLText(text[pos:]))
+
+	return sb.String()
+}
+
+func decodeHTMLText(text string) string {
+	return html.UnescapeString(reLineBreak.ReplaceAllString(text, "\n"))
+}
+
+// regexpProtector returns a protector keeping every match of re untouched
+func regexpProtector(re *regexp.Regexp) protector {
+	return func(text string) []chunk {
+		var chunks []chunk
+		pos := 0
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if loc[0] == loc[1] {
+				continue
+			}
+			if loc[0] > pos {
+				chunks = append(chunks, chunk{text: text[pos:loc[0]]})
+			}
+			chunks = append(chunks, chunk{text: text[loc[0]:loc[1]], keep: true})
+			pos = loc[1]
+		}
+		if pos < len(text) {
+			chunks = append(chunks, chunk{text: text[pos:]})
+		}
+
+		return chunks
+	}
+}
+
+func hasLetters(s string) bool {
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// Markup left alone by fakeTranslate: untranslatable spans, tags and entities
+var reFakeKeep = regexp.MustCompile(`(?s)<span translate="no">.*?</span>|<[^<>]*>|&[#\w]+;`)
+
+// fakeTranslate makes the translation APIs apply translate to the strings
+// sent, outside of the markup of HTML strings, until the end of the test. It
+// returns the strings sent so far.
+func fakeTranslate(t *testing.T, translate func(string) string) *[]string {
+	t.Helper()
+	sent := new([]string)
+	defer func(api func([]string, bool, string) ([]string, []string, error)) {
+		t.Cleanup(func() { translateAPI = api })
+	}(translateAPI)
+
+	translateAPI = func(strInp []string, useAdvanced bool, format string) ([]string, []string, error) {
+		*sent = append(*sent, strInp...)
+		strOut := make([]string, len(strInp))
+		detected := make([]string, len(strInp))
+		for i, s := range strInp {
+			if format != formatHTML {
+				strOut[i] = translate(s)
+				continue
+			}
+			var sb strings.Builder
+			pos := 0
+			for _, loc := range reFakeKeep.FindAllStringIndex(s, -1) {
+				sb.WriteString(translate(s[pos:loc[0]]))
+				sb.WriteString(s[loc[0]:loc[1]])
+				pos = loc[1]
+			}
+			sb.WriteString(translate(s[pos:]))
+			strOut[i] = sb.String()
+			detected[i] = "en"
+		}
+		return strOut, detected, nil
+	}
+
+	return sent
+}
+
+func TestTranslateSegments(t *testing.T) {
+	sent := fakeTranslate(t, strings.ToUpper)
+
+	segs := []segment{
+		{Text: "  Hello  "},
+		{Text: "42"},
+		{Text: "Hello"},
+		{Text: "Hello", Context: "greeting"},
+		{Text: "Hi, %s & bye"},
+	}
+	got, err := translateSegments(segs, regexpProtector(regexp.MustCompile(`%s`)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"  HELLO  ", "42", "HELLO", "HELLO", "HI, %s & BYE"}
+	if !slices.Equal(got, want) {
+		t.Errorf("translateSegments() = %q, want %q", got, want)
+	}
+	if sentWant := []string{"Hello", "Hello", `Hi, <span translate="no">%s</span> &amp; bye`}; !slices.Equal(*sent, sentWant) {
+		t.Errorf("sent %q, want %q", *sent, sentWant)
+	}
+}
@@ -0,0 +1,425 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+)
+
+// Maximum number of cues merged into one sentence
+const maxMergedCues = 6
+
+var (
+	// HTML-like tags of SRT/WebVTT (<i>, <font>, <c.yellow>, <v Bob>, <00:01.000>)
+	// and ASS override blocks ({\an8}) that often appear in SRT files as well
+	reSRTTag = regexp.MustCompile(`<[^<>]+>|\{\\[^{}]*\}`)
+	// ASS override blocks and hard spaces
+	reASSTag = regexp.MustCompile(`\{[^{}]*\}|\\h`)
+	// Punctuation ending a sentence, optionally followed by closing quotes
+	reSentenceEnd = regexp.MustCompile(`[.!?…。！？]["'»”’)\]]*$`)
+
+	reBlankLines = regexp.MustCompile(`\n[ \t]*\n\s*`)
+	reASSBreak   = regexp.MustCompile(`\\[Nn]`)
+)
+
+// subtitlesCmd represents the subtitles command
+var subtitlesCmd = &cobra.Command{
+	Use:   "subtitles",
+	Short: "Translate SRT, WebVTT and ASS/SSA subtitles",
+	Long: `Translates the text of subtitle cues. Cue numbers, timestamps, cue settings,
+styles and formatting tags are kept untouched; the format is chosen by the file
+extension (.srt, .vtt, .ass or .ssa).
+
+Cues that continue a sentence are merged before translation so that the whole
+sentence is translated in context. The translation is then split back over the
+same cues in proportion to the original text and re-wrapped to at most
+--max-lines lines of --max-line-chars characters. Use --merge-sentences=false to
+translate every cue on its own.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if subtitleMaxChars < 1 || subtitleMaxLines < 1 {
+			return fmt.Errorf("--max-line-chars and --max-lines must be positive")
+		}
+
+		return runFileHandler(translateSubtitles)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(subtitlesCmd)
+
+	subtitlesCmd.Flags().IntVarP(&subtitleMaxChars, "max-line-chars", "", 42, "Maximum number of characters per subtitle line")
+	subtitlesCmd.Flags().IntVarP(&subtitleMaxLines, "max-lines", "", 2, "Maximum number of lines per cue")
+	subtitlesCmd.Flags().BoolVarP(&subtitleMerge, "merge-sentences", "", true, "Merge cues belonging to one sentence before translation")
+	addEncodingFlags(subtitlesCmd)
+}
+
+// subtitleCue is the text of a single cue. Formatting tags wrapping the
+// whole cue are kept aside in prefix and suffix; tags inside the body are
+// protected during translation.
+type subtitleCue struct {
+	lines    []string // Text lines; replaced by the translated lines
+	dialogue bool     // Lines belong to different speakers ("- Hi" / "- Hello")
+	prefix   string
+	body     string // Lines joined with spaces, without prefix and suffix
+	suffix   string
+}
+
+// translateSubtitles is the fileHandler of the subtitles command
+func translateSubtitles(name string, data []byte) ([]byte, error) {
+	switch ext := strings.ToLower(filepath.Ext(name)); ext {
+	case ".srt", ".vtt":
+		return translateSubtitleBlocks(data)
+	case ".ass", ".ssa":
+		return translateASS(data)
+	default:
+		return nil, fmt.Errorf("unsupported subtitle format: %v", ext)
+	}
+}
+
+// **************************************************************************
+// translateSubtitleBlocks translates SRT and WebVTT files. Both consist of
+// blocks separated by blank lines; a block with a "-->" timing line is a cue
+// whose text follows the timing line. Other blocks (the WEBVTT header, NOTE,
+// STYLE and REGION blocks) are copied unchanged. Line endings and a leading
+// byte order mark are preserved.
+// --------------------------------------------------------------------------
+func translateSubtitleBlocks(data []byte) ([]byte, error) {
+	text := string(data)
+	bom := ""
+	if strings.HasPrefix(text, "\ufeff") {
+		bom, text = "\ufeff", text[3:]
+	}
+	newline := "\n"
+	if strings.Contains(text, "\r\n") {
+		newline = "\r\n"
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+	}
+
+	type block struct {
+		header []string // Lines up to and including the timing line
+		cue    *subtitleCue
+	}
+
+	var blocks []block
+	var cues []*subtitleCue
+	for _, raw := range reBlankLines.Split(strings.Trim(text, "\n"), -1) {
+		lines := strings.Split(raw, "\n")
+		b := block{header: lines}
+		for i, line := range lines {
+			if strings.Contains(line, "-->") {
+				b.header = lines[:i+1]
+				b.cue = newSubtitleCue(lines[i+1:], reSRTTag)
+				cues = append(cues, b.cue)
+				break
+			}
+		}
+		blocks = append(blocks, b)
+	}
+
+	if err := translateCues(cues, reSRTTag); err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(blocks))
+	for i, b := range blocks {
+		lines := b.header
+		if b.cue != nil {
+			lines = append(lines[:len(lines):len(lines)], b.cue.lines...)
+		}
+		parts[i] = strings.Join(lines, newline)
+	}
+
+	return []byte(bom + strings.Join(parts, newline+newline) + newline), nil
+}
+
+// **************************************************************************
+// translateASS translates the Dialogue events of an ASS/SSA script. The Text
+// field is located through the Format line of the [Events] section; \N
+// breaks separate the lines of a cue. Everything else, including Comment
+// events and styles, is copied unchanged.
+// --------------------------------------------------------------------------
+func translateASS(data []byte) ([]byte, error) {
+	lines := strings.Split(string(data), "\n")
+
+	type event struct {
+		line   int
+		fields []string
+		cue    *subtitleCue
+	}
+
+	var events []event
+	var cues []*subtitleCue
+	section := ""
+	nFields := 10 // Default number of fields of the ASS Events format
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "["):
+			section = strings.ToLower(trimmed)
+		case section != "[events]":
+		case strings.HasPrefix(trimmed, "Format:"):
+			nFields = len(strings.Split(trimmed, ","))
+		case strings.HasPrefix(trimmed, "Dialogue:"):
+			fields := strings.SplitN(strings.TrimRight(line, "\r"), ",", nFields)
+			if len(fields) < nFields {
+				continue
+			}
+			e := event{line: i, fields: fields}
+			e.cue = newSubtitleCue(reASSBreak.Split(fields[nFields-1], -1), reASSTag)
+			events = append(events, e)
+			cues = append(cues, e.cue)
+		}
+	}
+
+	if err := translateCues(cues, reASSTag); err != nil {
+		return nil, err
+	}
+
+	for _, e := range events {
+		e.fields[len(e.fields)-1] = strings.Join(e.cue.lines, `\N`)
+		cr := ""
+		if strings.HasSuffix(lines[e.line], "\r") {
+			cr = "\r"
+		}
+		lines[e.line] = strings.Join(e.fields, ",") + cr
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+// newSubtitleCue splits the text lines of a cue into prefix, body and suffix
+func newSubtitleCue(lines []string, tags *regexp.Regexp) *subtitleCue {
+	c := &subtitleCue{lines: lines}
+
+	dashes := 0
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(tags.ReplaceAllString(line, "")), "-") {
+			dashes++
+		}
+	}
+	c.dialogue = len(lines) > 1 && dashes == len(lines)
+
+	body := strings.TrimSpace(strings.Join(lines, " "))
+	for {
+		loc := tags.FindStringIndex(body)
+		if loc == nil || loc[0] != 0 {
+			break
+		}
+		c.prefix += body[:loc[1]]
+		body = body[loc[1]:]
+	}
+	for {
+		locs := tags.FindAllStringIndex(body, -1)
+		if len(locs) == 0 || locs[len(locs)-1][1] != len(body) {
+			break
+		}
+		last := locs[len(locs)-1]
+		c.suffix = body[last[0]:] + c.suffix
+		body = body[:last[0]]
+	}
+	c.body = body
+
+	return c
+}
+
+// **************************************************************************
+// translateCues translates the cues in place. Consecutive cues are merged
+// into sentences (unless disabled), each sentence is translated as a single
+// segment and the translation is distributed back over its cues. Dialogue
+// cues are translated line by line and never merged.
+// --------------------------------------------------------------------------
+func translateCues(cues []*subtitleCue, tags *regexp.Regexp) error {
+	var groups [][]*subtitleCue
+	var current []*subtitleCue
+	flush := func() {
+		if len(current) > 0 {
+			groups = append(groups, current)
+			current = nil
+		}
+	}
+	for _, c := range cues {
+		if c.dialogue || c.body == "" {
+			flush()
+			groups = append(groups, []*subtitleCue{c})
+			continue
+		}
+		current = append(current, c)
+		plain := strings.TrimSpace(tags.ReplaceAllString(c.body, ""))
+		if !subtitleMerge || reSentenceEnd.MatchString(plain) || len(current) >= maxMergedCues {
+			flush()
+		}
+	}
+	flush()
+
+	var segs []segment
+	for _, g := range groups {
+		if g[0].dialogue {
+			for _, line := range g[0].lines {
+				segs = append(segs, segment{Text: line})
+			}
+			continue
+		}
+		bodies := make([]string, len(g))
+		for i, c := range g {
+			bodies[i] = c.body
+		}
+		segs = append(segs, segment{Text: strings.Join(bodies, " ")})
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(tags))
+	if err != nil {
+		return err
+	}
+
+	k := 0
+	for _, g := range groups {
+		if g[0].dialogue {
+			copy(g[0].lines, strOut[k:k+len(g[0].lines)])
+			k += len(g[0].lines)
+			continue
+		}
+		if g[0].body == "" {
+			k++
+			continue
+		}
+
+		weights := make([]int, len(g))
+		for i, c := range g {
+			weights[i] = visibleLength(c.body, tags)
+		}
+		for i, part := range splitProportional(strOut[k], weights) {
+			c := g[i]
+			c.lines = wrapSubtitle(part, tags)
+			c.lines[0] = c.prefix + c.lines[0]
+			c.lines[len(c.lines)-1] += c.suffix
+		}
+		k++
+	}
+
+	return nil
+}
+
+// **************************************************************************
+// splitProportional splits text into len(weights) parts whose lengths are
+// proportional to the weights. Cuts are moved to the nearest space so that
+// words stay whole; text without spaces (e.g. Chinese or Japanese) is cut
+// between characters.
+// --------------------------------------------------------------------------
+func splitProportional(text string, weights []int) []string {
+	parts := make([]string, len(weights))
+	if len(weights) == 1 {
+		parts[0] = text
+		return parts
+	}
+
+	total := 0
+	for _, w := range weights {
+		total += max(w, 1)
+	}
+	runes := []rune(text)
+	spaced := strings.ContainsRune(text, ' ')
+
+	start, acc := 0, 0
+	for i := range weights[:len(weights)-1] {
+		acc += max(weights[i], 1)
+		cut := len(runes) * acc / total
+		if spaced {
+			cut = nearestSpace(runes, cut, start)
+		}
+		cut = max(cut, start)
+		parts[i] = strings.TrimSpace(string(runes[start:cut]))
+		start = cut
+	}
+	parts[len(parts)-1] = strings.TrimSpace(string(runes[start:]))
+
+	return parts
+}
+
+// nearestSpace returns the position of the space closest to pos, after lo
+func nearestSpace(runes []rune, pos, lo int) int {
+	for d := 0; d < len(runes); d++ {
+		if p := pos - d; p > lo && p < len(runes) && runes[p] == ' ' {
+			return p
+		}
+		if p := pos + d; p > lo && p < len(runes) && runes[p] == ' ' {
+			return p
+		}
+	}
+
+	return pos
+}
+
+// **************************************************************************
+// wrapSubtitle breaks the text of a cue into lines of at most
+// subtitleMaxChars visible characters. When that would take more than
+// subtitleMaxLines lines, the text is spread evenly over subtitleMaxLines
+// longer lines instead, since dropping text is never an option.
+// --------------------------------------------------------------------------
+func wrapSubtitle(text string, tags *regexp.Regexp) []string {
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return []string{""}
+	}
+
+	width := subtitleMaxChars
+	lines := wrapWords(words, width, tags)
+	if len(lines) > subtitleMaxLines {
+		width = (visibleLength(text, tags) + subtitleMaxLines - 1) / subtitleMaxLines
+		for len(lines) > subtitleMaxLines {
+			lines = wrapWords(words, width, tags)
+			width++
+		}
+	}
+
+	return lines
+}
+
+func wrapWords(words []string, width int, tags *regexp.Regexp) []string {
+	var lines []string
+	line, length := "", 0
+	for _, word := range words {
+		n := visibleLength(word, tags)
+		if line != "" && length+1+n > width {
+			lines = append(lines, line)
+			line, length = "", 0
+		}
+		if line != "" {
+			line += " "
+			length++
+		}
+		line += word
+		length += n
+	}
+
+	return append(lines, line)
+}
+
+// visibleLength counts the characters of s that are not formatting tags;
+// tags may be nil for plain text
+func visibleLength(s string, tags *regexp.Regexp) int {
+	if tags != nil {
+		s = tags.ReplaceAllString(s, "")
+	}
+
+	return utf8.RuneCountInString(strings.TrimFunc(s, unicode.IsSpace))
+}
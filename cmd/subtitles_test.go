@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestSplitProportional(t *testing.T) {
+	tests := []struct {
+		text    string
+		weights []int
+		want    []string
+	}{
+		{"one two three four", []int{1}, []string{"one two three four"}},
+		{"aaaa bbbb cccc dddd", []int{10, 10}, []string{"aaaa bbbb", "cccc dddd"}},
+		{"aaaa bbbb cccc dddd", []int{5, 15}, []string{"aaaa", "bbbb cccc dddd"}},
+		{"一二三四五六", []int{1, 2}, []string{"一二", "三四五六"}},
+		{"word", []int{3, 3}, []string{"wo", "rd"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitProportional(tt.text, tt.weights); !slices.Equal(got, tt.want) {
+			t.Errorf("splitProportional(%q, %v) = %q, want %q", tt.text, tt.weights, got, tt.want)
+		}
+	}
+}
+
+func TestWrapSubtitle(t *testing.T) {
+	defer func(chars, lines int) { subtitleMaxChars, subtitleMaxLines = chars, lines }(subtitleMaxChars, subtitleMaxLines)
+	subtitleMaxChars, subtitleMaxLines = 12, 2
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{""}},
+		{"Short one", []string{"Short one"}},
+		{"Two lines of <i>text</i>", []string{"Two lines of", "<i>text</i>"}},
+		{"This is far too long for two lines", []string{"This is far too", "long for two lines"}},
+	}
+
+	for _, tt := range tests {
+		if got := wrapSubtitle(tt.text, reSRTTag); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapSubtitle(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateSubtitleBlocks(t *testing.T) {
+	defer func(merge bool, chars, lines int) {
+		subtitleMerge, subtitleMaxChars, subtitleMaxLines = merge, chars, lines
+	}(subtitleMerge, subtitleMaxChars, subtitleMaxLines)
+	subtitleMerge, subtitleMaxChars, subtitleMaxLines = true, 42, 2
+	sent := fakeTranslate(t, strings.ToUpper)
+
+	data := "WEBVTT\r\n\r\n" +
+		"1\r\n00:00:01.000 --> 00:00:02.000\r\n<i>I think that</i>\r\n\r\n" +
+		"2\r\n00:00:02.000 --> 00:00:03.000 align:start\r\nwe should go home.\r\n\r\n" +
+		"3\r\n00:00:04.000 --> 00:00:05.000\r\n- Now?\r\n- Yes.\r\n"
+	got, err := translateSubtitleBlocks([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := "WEBVTT\r\n\r\n" +
+		"1\r\n00:00:01.000 --> 00:00:02.000\r\n<i>I THINK THAT</i>\r\n\r\n" +
+		"2\r\n00:00:02.000 --> 00:00:03.000 align:start\r\nWE SHOULD GO HOME.\r\n\r\n" +
+		"3\r\n00:00:04.000 --> 00:00:05.000\r\n- NOW?\r\n- YES.\r\n"
+	if string(got) != want {
+		t.Errorf("translateSubtitleBlocks() = %q, want %q", got, want)
+	}
+	// The first two cues are one sentence; dialogue lines go on their own
+	if want := []string{"I think that we should go home.", "- Now?", "- Yes."}; !slices.Equal(*sent, want) {
+		t.Errorf("sent %q, want %q", *sent, want)
+	}
+}
+
+func TestTranslateASS(t *testing.T) {
+	fakeTranslate(t, strings.ToUpper)
+
+	data := "[Script Info]\nTitle: Demo\n\n[Events]\n" +
+		"Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text\n" +
+		"Dialogue: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,{\\an8}Hello, world.\r\n" +
+		"Comment: 0,0:00:01.00,0:00:02.00,Default,,0,0,0,,Not this\n"
+	got, err := translateASS([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := strings.Replace(data, "Hello, world.", "HELLO, WORLD.", 1)
+	if string(got) != want {
+		t.Errorf("translateASS() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2/unstable"
+	"github.com/spf13/cobra"
+)
+
+// Go template actions, {0}/{name} arguments, Rails interpolations and printf verbs
+var reTOMLPlaceholder = regexp.MustCompile(`(?s)\{\{.*?\}\}|%?\{[^{}]*\}|` + printfVerbPattern)
+
+// Keys of message catalogs that hold metadata rather than text
+var tomlMetadataKeys = []string{"id", "description", "hash", "leftdelim", "rightdelim"}
+
+// tomlCmd represents the toml command
+var tomlCmd = &cobra.Command{
+	Use:   "toml",
+	Short: "Translate TOML message catalogs",
+	Long: `Translates the string values of TOML message catalogs such as go-i18n active.en.toml.
+Only the string literals are rewritten, so comments, tables and layout are kept.
+Values of id, description, hash, leftDelim and rightDelim keys are left alone and
+the description of a message is used as the context of its other strings.
+Placeholders like {{.Name}}, {0} and %s are never sent for translation.
+
+When --output is an existing directory the language part of the file name is
+replaced: en.toml becomes uk.toml and active.en.toml becomes active.uk.toml.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if isDir(outputFile) {
+			outputFile = filepath.Join(outputFile, localizedCatalogName(filepath.Base(inputFile), targetLang))
+		}
+
+		return runFileHandler(translateTOML)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tomlCmd)
+}
+
+// tomlString is a string value of a TOML document with its key path and the
+// byte range of the literal
+type tomlString struct {
+	path  []string
+	value string
+	start int
+	end   int
+}
+
+// **************************************************************************
+// translateTOML is the fileHandler of the toml command. The document is
+// parsed with the low-level go-toml parser, which reports the position of
+// every literal, and each translated string is written back as a basic
+// string in place of the original literal.
+// --------------------------------------------------------------------------
+func translateTOML(name string, data []byte) ([]byte, error) {
+	var strs []tomlString
+	var table []string
+
+	p := unstable.Parser{}
+	p.Reset(data)
+	for p.NextExpression() {
+		expr := p.Expression()
+		switch expr.Kind {
+		case unstable.Table, unstable.ArrayTable:
+			table = tomlKey(expr.Key())
+		case unstable.KeyValue:
+			path := append(slices.Clone(table), tomlKey(expr.Key())...)
+			strs = collectTOMLStrings(expr.Value(), path, strs)
+		}
+	}
+	if err := p.Error(); err != nil {
+		return nil, fmt.Errorf("invalid TOML: %v", err)
+	}
+
+	descriptions := make(map[string]string)
+	for _, s := range strs {
+		if n := len(s.path); n > 0 && strings.EqualFold(s.path[n-1], "description") {
+			descriptions[strings.Join(s.path[:n-1], ".")] = s.value
+		}
+	}
+
+	var edits []xmlEdit
+	var segs []segment
+	for _, s := range strs {
+		n := len(s.path)
+		if n > 0 && slices.Contains(tomlMetadataKeys, strings.ToLower(s.path[n-1])) {
+			continue
+		}
+		context := ""
+		if n > 0 {
+			context = descriptions[strings.Join(s.path[:n-1], ".")]
+		}
+		edits = append(edits, xmlEdit{start: s.start, end: s.end})
+		segs = append(segs, segment{Text: s.value, Context: context})
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(reTOMLPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, text := range strOut {
+		edits[i].text = quoteTOMLString(text)
+	}
+
+	return applyXMLEdits(data, edits), nil
+}
+
+// collectTOMLStrings appends the strings of a value, descending into arrays
+// and inline tables
+func collectTOMLStrings(value *unstable.Node, path []string, strs []tomlString) []tomlString {
+	switch value.Kind {
+	case unstable.String:
+		strs = append(strs, tomlString{
+			path:  path,
+			value: string(value.Data),
+			start: int(value.Raw.Offset),
+			end:   int(value.Raw.Offset + value.Raw.Length),
+		})
+	case unstable.Array:
+		it := value.Children()
+		for it.Next() {
+			strs = collectTOMLStrings(it.Node(), path, strs)
+		}
+	case unstable.InlineTable:
+		it := value.Children()
+		for it.Next() {
+			kv := it.Node()
+			strs = collectTOMLStrings(kv.Value(), append(slices.Clone(path), tomlKey(kv.Key())...), strs)
+		}
+	}
+
+	return strs
+}
+
+// tomlKey returns the parts of a dotted key
+func tomlKey(it unstable.Iterator) []string {
+	var key []string
+	for it.Next() {
+		key = append(key, string(it.Node().Data))
+	}
+
+	return key
+}
+
+// quoteTOMLString encodes s as a TOML basic string
+func quoteTOMLString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"':
+			sb.WriteString(`\"`)
+		case r == '\\':
+			sb.WriteString(`\\`)
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&sb, `\u%04X`, r)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+
+	return sb.String()
+}
+
+// localizedCatalogName replaces the language part of a catalog file name:
+// en.toml -> uk.toml, active.en.toml -> active.uk.toml
+func localizedCatalogName(base, lang string) string {
+	ext := filepath.Ext(base)
+	parts := strings.Split(strings.TrimSuffix(base, ext), ".")
+	last := len(parts) - 1
+	if reLanguageTag.MatchString(parts[last]) {
+		parts[last] = lang
+	} else {
+		parts = append(parts, lang)
+	}
+
+	return strings.Join(parts, ".") + ext
+}
+
+var reLanguageTag = regexp.MustCompile(`^[a-z]{2,3}([-_][A-Za-z0-9]{2,8})*$`)
@@ -7,6 +7,10 @@ The translation system is built around three main functions:
 1. translateEx - The main entry point that orchestrates the translation process
 2. translateBasic - Handles translation using the Basic Google Translate API
 3. translateAdvanced - Handles translation using the Advanced Google Translate API v3
+
+translateExFormat is a variant of translateEx for callers that need to send
+HTML markup (e.g. to mark placeholders as untranslatable), and translateExDetect
+also returns the source language of every string.
 */
 package cmd
 
@@ -23,6 +27,12 @@ import (
 	"cloud.google.com/go/translate/apiv3/translatepb"
 )
 
+// Input formats understood by both translation APIs
+const (
+	formatText = "text" // Plain text, returned as is
+	formatHTML = "html" // HTML markup, tags are kept and entities are escaped
+)
+
 // **************************************************************************
 // translateEx serves as the main entry point for the translation system,
 // orchestrating the translation process by delegating to either the Basic
@@ -61,20 +71,46 @@ import (
 // each translated string corresponds to its original input string.
 // --------------------------------------------------------------------------
 func translateEx(strInp []string, useAdvanced bool) (strOut []string, err error) {
-	// Choose between Basic and Advanced API based on the flag
-	if useAdvanced {
-		strOut, err = translateAdvanced(strInp)
-		// fmt.Printf("Successfully translated %s to %s using Advanced API\n", inputFile, outputFile)
-	} else {
-		strOut, err = translateBasic(strInp)
-		// fmt.Printf("Successfully translated %s to %s using Basic API\n", inputFile, outputFile)
-	}
+	return translateExFormat(strInp, useAdvanced, formatText)
+}
+
+// **************************************************************************
+// translateExFormat works like translateEx but lets the caller choose the
+// input format: formatText for plain strings or formatHTML for markup. In
+// HTML mode the APIs leave tags alone and skip the content of elements
+// marked with translate="no", which is how structured file handlers keep
+// placeholders intact.
+// --------------------------------------------------------------------------
+func translateExFormat(strInp []string, useAdvanced bool, format string) (strOut []string, err error) {
+	strOut, _, err = translateExDetect(strInp, useAdvanced, format)
+
+	return strOut, err
+}
 
+// **************************************************************************
+// translateExDetect works like translateExFormat and also returns the source
+// language of every string: the language detected by the API when sourceLang
+// is "auto", otherwise sourceLang itself. A language the API could not
+// detect is returned as "".
+// --------------------------------------------------------------------------
+func translateExDetect(strInp []string, useAdvanced bool, format string) (strOut []string, detected []string, err error) {
+	strOut, detected, err = translateAPI(strInp, useAdvanced, format)
 	if err != nil {
-		return strOut, fmt.Errorf("failed to translate text: %v", err)
+		return strOut, detected, fmt.Errorf("failed to translate text: %v", err)
+	}
+
+	return strOut, detected, nil
+}
+
+// translateAPI sends strings to the Basic or Advanced API. It is a variable
+// so that tests can translate without network access.
+var translateAPI = func(strInp []string, useAdvanced bool, format string) ([]string, []string, error) {
+	// Choose between Basic and Advanced API based on the flag
+	if useAdvanced {
+		return translateAdvanced(strInp, format)
 	}
 
-	return strOut, nil
+	return translateBasic(strInp, format)
 }
 
 // **************************************************************************
@@ -90,9 +126,11 @@ func translateEx(strInp []string, useAdvanced bool) (strOut []string, err error)
 //
 // Parameters:
 //   - strInp []string: Slice of strings to translate
+//   - format string: formatText or formatHTML
 //
 // Returns:
 //   - []string: Slice of translated strings
+//   - []string: Source language of each string (see translateExDetect)
 //   - error: Any error that occurred during translation
 //
 // The function uses several global variables:
@@ -109,7 +147,7 @@ func translateEx(strInp []string, useAdvanced bool) (strOut []string, err error)
 // Note: The Basic API is often sufficient for simple translation needs
 // and doesn't require project setup in Google Cloud.
 // --------------------------------------------------------------------------
-func translateBasic(strInp []string) (strOut []string, err error) {
+func translateBasic(strInp []string, format string) (strOut []string, detected []string, err error) {
 	// Set up Google Cloud credentials if provided
 	if credentials != "" {
 		os.Setenv("GOOGLE_APPLICATION_CREDENTIALS", credentials)
@@ -128,14 +166,14 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 	}
 
 	if clientErr != nil {
-		return strOut, fmt.Errorf("failed to create client: %v", clientErr)
+		return strOut, detected, fmt.Errorf("failed to create client: %v", clientErr)
 	}
 	defer client.Close()
 
 	// Parse the target language code
 	targetLangTag, err := language.Parse(targetLang)
 	if err != nil {
-		return strOut, fmt.Errorf("invalid target language code: %v", err)
+		return strOut, detected, fmt.Errorf("invalid target language code: %v", err)
 	}
 
 	var translations []translateBas.Translation
@@ -145,13 +183,13 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 			strInp,
 			targetLangTag,
 			&translateBas.Options{
-				Format: translateBas.Text,
+				Format: translateBas.Format(format),
 			})
 	} else {
 		// If source language is specified, parse and use it
 		sourceLangTag, err := language.Parse(sourceLang)
 		if err != nil {
-			return strOut, fmt.Errorf("invalid source language code: %v", err)
+			return strOut, detected, fmt.Errorf("invalid source language code: %v", err)
 		}
 
 		translations, err = client.Translate(ctx,
@@ -159,16 +197,16 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 			targetLangTag,
 			&translateBas.Options{
 				Source: sourceLangTag,
-				Format: translateBas.Text,
+				Format: translateBas.Format(format),
 			})
 	}
 
 	if err != nil {
-		return strOut, fmt.Errorf("failed to translate text: %v", err)
+		return strOut, detected, fmt.Errorf("failed to translate text: %v", err)
 	}
 
 	if len(translations) == 0 {
-		return strOut, fmt.Errorf("no translation returned")
+		return strOut, detected, fmt.Errorf("no translation returned")
 	}
 
 	// if sourceLang == "auto" {
@@ -180,9 +218,16 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 
 	for _, tra := range translations {
 		strOut = append(strOut, tra.Text)
+		if sourceLang != "auto" {
+			detected = append(detected, sourceLang)
+		} else if tra.Source != language.Und {
+			detected = append(detected, tra.Source.String())
+		} else {
+			detected = append(detected, "")
+		}
 	}
 
-	return strOut, nil
+	return strOut, detected, nil
 }
 
 // **************************************************************************
@@ -198,9 +243,11 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 //
 // Parameters:
 //   - strInp []string: Slice of strings to translate
+//   - format string: formatText or formatHTML
 //
 // Returns:
 //   - []string: Slice of translated strings
+//   - []string: Source language of each string (see translateExDetect)
 //   - error: Any error that occurred during translation
 //
 // Required global variables:
@@ -224,10 +271,10 @@ func translateBasic(strInp []string) (strOut []string, err error) {
 // Note: This function requires proper Google Cloud project setup
 // and appropriate API permissions.
 // --------------------------------------------------------------------------
-func translateAdvanced(strInp []string) (strOut []string, err error) {
+func translateAdvanced(strInp []string, format string) (strOut []string, detected []string, err error) {
 	// Verify project ID is provided (required for Advanced API)
 	if projectID == "" {
-		return strOut, fmt.Errorf("project ID is required for Advanced API")
+		return strOut, detected, fmt.Errorf("project ID is required for Advanced API")
 	}
 
 	// Set up Google Cloud credentials if provided
@@ -249,7 +296,7 @@ func translateAdvanced(strInp []string) (strOut []string, err error) {
 	}
 
 	if clientErr != nil {
-		return strOut, fmt.Errorf("failed to create client: %v", clientErr)
+		return strOut, detected, fmt.Errorf("failed to create client: %v", clientErr)
 	}
 	defer client.Close()
 
@@ -260,6 +307,9 @@ func translateAdvanced(strInp []string) (strOut []string, err error) {
 		TargetLanguageCode: targetLang,
 		MimeType:           "text/plain", // Specify plain text format
 	}
+	if format == formatHTML {
+		req.MimeType = "text/html"
+	}
 
 	// Add source language if specified (not auto)
 	if sourceLang != "auto" {
@@ -269,18 +319,23 @@ func translateAdvanced(strInp []string) (strOut []string, err error) {
 	// Perform the translation
 	resp, err := client.TranslateText(ctx, req)
 	if err != nil {
-		return strOut, fmt.Errorf("failed to translate text: %v", err)
+		return strOut, detected, fmt.Errorf("failed to translate text: %v", err)
 	}
 
 	if len(resp.GetTranslations()) == 0 {
-		return strOut, fmt.Errorf("no translation returned")
+		return strOut, detected, fmt.Errorf("no translation returned")
 	}
 
 	for _, tra := range resp.GetTranslations() {
 		strOut = append(strOut, tra.GetTranslatedText())
+		if sourceLang != "auto" {
+			detected = append(detected, sourceLang)
+		} else {
+			detected = append(detected, tra.GetDetectedLanguageCode())
+		}
 	}
 
 	// fmt.Printf("Source language: %s, Target language: %s\n", resp.GetTranslations()[0].GetDetectedLanguageCode(), targetLang)
 
-	return strOut, nil
+	return strOut, detected, nil
 }
@@ -0,0 +1,415 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// xmlCmd represents the xml command
+var xmlCmd = &cobra.Command{
+	Use:   "xml",
+	Short: "Translate selected text and attributes of XML files",
+	Long: `Translates arbitrary XML documents such as sitemaps, product feeds or DITA
+topics. Only the selected text and attribute values are rewritten; declarations,
+namespaces, comments, processing instructions and CDATA sections are copied
+byte-for-byte. A document declaring an encoding such as ISO-8859-1 is written
+back in it, and references to entities declared in a DTD are kept as written.
+
+What to translate is chosen with --xpath, which accepts a subset of XPath:
+
+  //title                    the text of every title element
+  /rss/channel/item/description
+  //p[@audience='user']      attribute predicates
+  //item[1]/name             positions among siblings of the same name
+  //image/@alt               attribute values
+  //*/@title                 attributes of any element
+  //shortdesc/text()         only the direct text of elements
+
+The text of a selected element, including its inline children like <b> or
+<uicontrol>, is translated as one sentence and the child elements keep their
+place. Without --xpath every text node is translated.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		exprs := xmlXPath
+		if len(exprs) == 0 {
+			exprs = []string{"//text()"}
+		}
+		selectors := make([]xpathExpr, 0, len(exprs))
+		for _, expr := range exprs {
+			sel, err := parseXPath(expr)
+			if err != nil {
+				return err
+			}
+			selectors = append(selectors, sel)
+		}
+
+		return runFileHandler(func(name string, data []byte) ([]byte, error) {
+			return translateXML(data, selectors)
+		})
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(xmlCmd)
+
+	xmlCmd.Flags().StringArrayVarP(&xmlXPath, "xpath", "", []string{}, "XPath of the elements or attributes to translate (can be specified multiple times)")
+}
+
+// xpathStep is a location step matching an element
+type xpathStep struct {
+	name     string // Qualified element name or *
+	deep     bool   // Preceded by // rather than /
+	attr     string // Attribute the element must have, if not empty
+	value    string // Value of attr, when hasValue is set
+	hasValue bool
+	index    int // Position among siblings, if positive
+}
+
+// xpathExpr is a parsed selector: the element steps and what is taken from
+// the matched elements
+type xpathExpr struct {
+	steps []xpathStep
+	attr  string // Selected attribute, or * for all of them
+	text  bool   // Only the direct text of the elements
+}
+
+// xpathNode is an element on the path from the root to the current token
+type xpathNode struct {
+	el  xml.StartElement
+	pos int // Position among siblings of the same name
+	any int // Position among all element siblings
+}
+
+// **************************************************************************
+// parseXPath parses a selector. Expressions not starting with / are relative
+// to any element, i.e. title is the same as //title.
+// --------------------------------------------------------------------------
+func parseXPath(expr string) (xpathExpr, error) {
+	var sel xpathExpr
+	rest := expr
+	if !strings.HasPrefix(rest, "/") {
+		rest = "//" + rest
+	}
+
+	for rest != "" {
+		if sel.attr != "" || sel.text {
+			return sel, fmt.Errorf("invalid XPath %q: attribute or text() must be the last step", expr)
+		}
+
+		var step xpathStep
+		switch {
+		case strings.HasPrefix(rest, "//"):
+			step.deep, rest = true, rest[2:]
+		case strings.HasPrefix(rest, "/"):
+			rest = rest[1:]
+		default:
+			return sel, fmt.Errorf("invalid XPath %q: expected / before %q", expr, rest)
+		}
+
+		// A step ends at the next / outside of predicates
+		end, depth := len(rest), 0
+		for i, r := range rest {
+			if r == '[' {
+				depth++
+			} else if r == ']' {
+				depth--
+			} else if r == '/' && depth == 0 {
+				end = i
+				break
+			}
+		}
+		text := rest[:end]
+		rest = rest[end:]
+
+		switch {
+		case text == "":
+			return sel, fmt.Errorf("invalid XPath %q: empty step", expr)
+		case strings.HasPrefix(text, "@"):
+			sel.attr = text[1:]
+			if len(sel.steps) == 0 || step.deep {
+				// //@alt selects the attribute on any element
+				sel.steps = append(sel.steps, xpathStep{name: "*", deep: true})
+			}
+			continue
+		case text == "text()":
+			sel.text = true
+			if len(sel.steps) == 0 || step.deep {
+				sel.steps = append(sel.steps, xpathStep{name: "*", deep: true})
+			}
+			continue
+		}
+
+		name, preds, _ := strings.Cut(text, "[")
+		step.name = name
+		for preds != "" {
+			pred, tail, ok := strings.Cut(preds, "]")
+			if !ok {
+				return sel, fmt.Errorf("invalid XPath %q: missing ]", expr)
+			}
+			if err := step.predicate(pred); err != nil {
+				return sel, fmt.Errorf("invalid XPath %q: %v", expr, err)
+			}
+			preds = strings.TrimPrefix(tail, "[")
+		}
+		sel.steps = append(sel.steps, step)
+	}
+
+	return sel, nil
+}
+
+var reXPathAttrPredicate = regexp.MustCompile(`^@([\w:.-]+)(?:\s*=\s*(?:'([^']*)'|"([^"]*)"))?$`)
+
+// predicate adds a predicate like [2], [@id] or [@lang='en'] to the step
+func (step *xpathStep) predicate(pred string) error {
+	pred = strings.TrimSpace(pred)
+	if index, err := strconv.Atoi(pred); err == nil && index > 0 {
+		step.index = index
+		return nil
+	}
+
+	m := reXPathAttrPredicate.FindStringSubmatch(pred)
+	if m == nil {
+		return fmt.Errorf("unsupported predicate [%v]", pred)
+	}
+	step.attr = m[1]
+	if strings.Contains(pred, "=") {
+		step.value, step.hasValue = m[2]+m[3], true
+	}
+
+	return nil
+}
+
+// matches reports whether the element satisfies the step
+func (step *xpathStep) matches(n xpathNode) bool {
+	if step.name != "*" && step.name != qualifiedName(n.el.Name) {
+		return false
+	}
+	pos := n.pos
+	if step.name == "*" {
+		pos = n.any
+	}
+	if step.index > 0 && step.index != pos {
+		return false
+	}
+	if step.attr != "" {
+		value, ok := qualifiedAttr(n.el, step.attr)
+		if !ok || step.hasValue && value != step.value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matches reports whether the path from the root to an element, given as
+// the chain of its ancestors and itself, is selected by the steps
+func (sel *xpathExpr) matches(chain []xpathNode) bool {
+	var match func(s, c int) bool
+	match = func(s, c int) bool {
+		if !sel.steps[s].matches(chain[c]) {
+			return false
+		}
+		if s == 0 {
+			return sel.steps[0].deep || c == 0
+		}
+		if !sel.steps[s].deep {
+			return c > 0 && match(s-1, c-1)
+		}
+		for k := c - 1; k >= 0; k-- {
+			if match(s-1, k) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return len(sel.steps) > 0 && len(chain) > 0 && match(len(sel.steps)-1, len(chain)-1)
+}
+
+// xmlAttrEdit is a selected attribute value
+type xmlAttrEdit struct {
+	xmlEdit
+	value string
+}
+
+// **************************************************************************
+// translateXML translates the text and attributes chosen by the selectors.
+// Selected elements become paragraphs of a markup document, so their text is
+// rewritten piece by piece around the inline markup; selected attributes are
+// rewritten inside their start tags.
+// --------------------------------------------------------------------------
+func translateXML(data []byte, selectors []xpathExpr) ([]byte, error) {
+	data, enc, err := decodeXML(data)
+	if err != nil {
+		return nil, err
+	}
+	toks, err := scanXML(data)
+	if err != nil {
+		return nil, err
+	}
+
+	type frame struct {
+		para   *markupParagraph // Paragraph started by the element, if selected
+		text   bool             // Whether the direct text is selected
+		counts map[string]int   // Number of child elements by name
+		any    int              // Number of child elements
+	}
+
+	doc := &markupDoc{data: data, toks: toks}
+	var attrs []xmlAttrEdit
+	var chain []xpathNode
+	frames := []*frame{{counts: map[string]int{}}}
+	var paras []*markupParagraph
+	for i, t := range toks {
+		switch tok := t.tok.(type) {
+		case xml.StartElement:
+			parent := frames[len(frames)-1]
+			parent.any++
+			parent.counts[qualifiedName(tok.Name)]++
+			chain = append(chain, xpathNode{el: tok, pos: parent.counts[qualifiedName(tok.Name)], any: parent.any})
+
+			f := &frame{counts: map[string]int{}}
+			for k := range selectors {
+				sel := &selectors[k]
+				if !sel.matches(chain) {
+					continue
+				}
+				switch {
+				case sel.attr != "":
+					attrs = appendAttrEdits(attrs, data, t, sel.attr)
+				case sel.text:
+					f.text = true
+				case f.para == nil:
+					f.para = &markupParagraph{}
+					paras = append(paras, f.para)
+				}
+			}
+			frames = append(frames, f)
+		case xml.EndElement:
+			if len(frames) > 1 {
+				frames = frames[:len(frames)-1]
+				chain = chain[:len(chain)-1]
+			}
+		case xml.CharData:
+			f := frames[len(frames)-1]
+			if f.text {
+				doc.paras = append(doc.paras, &markupParagraph{
+					richParagraph: richParagraph{pieces: []string{string(tok)}, seps: []string{""}},
+					units:         []int{i},
+				})
+				break
+			}
+			// The text belongs to the innermost selected element
+			for k := len(frames) - 1; k >= 0; k-- {
+				if p := frames[k].para; p != nil {
+					p.units = append(p.units, i)
+					p.pieces = append(p.pieces, string(tok))
+					p.seps = append(p.seps, "")
+					break
+				}
+			}
+		case xmlEntityRef:
+			for k := len(frames) - 1; k >= 0; k-- {
+				if p := frames[k].para; p != nil {
+					if n := len(p.seps); n > 0 {
+						p.seps[n-1] += tok.html()
+					}
+					break
+				}
+			}
+		}
+	}
+	for _, p := range paras {
+		if len(p.units) > 0 {
+			doc.paras = append(doc.paras, p)
+		}
+	}
+
+	if err := translateRichParagraphs(richParagraphs(doc)); err != nil {
+		return nil, err
+	}
+
+	segs := make([]segment, len(attrs))
+	for k, a := range attrs {
+		segs[k] = segment{Text: a.value}
+	}
+	strOut, err := translateSegments(segs, regexpProtector(reXMLEntityMark))
+	if err != nil {
+		return nil, err
+	}
+	edits := make([]xmlEdit, 0, len(attrs))
+	for k, a := range attrs {
+		if strOut[k] == a.value {
+			continue
+		}
+		quote := data[a.start]
+		a.text = string(quote) + escapeXMLAttr(strOut[k], quote) + string(quote)
+		edits = append(edits, a.xmlEdit)
+	}
+
+	return encodeXML(doc.rewrite(edits...), enc)
+}
+
+var reXMLAttribute = regexp.MustCompile(`\s([^\s=/<>]+)\s*=\s*("[^"]*"|'[^']*')`)
+
+// appendAttrEdits appends the values of the attributes of a start tag with
+// the given qualified name, or all of them for *. Namespace declarations are
+// never selected.
+func appendAttrEdits(attrs []xmlAttrEdit, data []byte, t xmlToken, name string) []xmlAttrEdit {
+	el := t.tok.(xml.StartElement)
+	for _, loc := range reXMLAttribute.FindAllSubmatchIndex(data[t.start:t.end], -1) {
+		qname := string(data[t.start+loc[2] : t.start+loc[3]])
+		if name != "*" && name != qname || qname == "xmlns" || strings.HasPrefix(qname, "xmlns:") {
+			continue
+		}
+		edit := xmlAttrEdit{xmlEdit: xmlEdit{start: t.start + loc[4], end: t.start + loc[5]}}
+		if slices.ContainsFunc(attrs, func(a xmlAttrEdit) bool { return a.start == edit.start }) {
+			continue // Already selected by another expression
+		}
+		edit.value, _ = qualifiedAttr(el, qname)
+		attrs = append(attrs, edit)
+	}
+
+	return attrs
+}
+
+// qualifiedAttr returns the value of the attribute with a qualified name
+func qualifiedAttr(el xml.StartElement, name string) (string, bool) {
+	for _, a := range el.Attr {
+		if qualifiedName(a.Name) == name {
+			return a.Value, true
+		}
+	}
+
+	return "", false
+}
+
+// escapeXMLAttr escapes an attribute value delimited by quote
+func escapeXMLAttr(s string, quote byte) string {
+	s = escapeXMLText(s)
+	if quote == '"' {
+		return strings.ReplaceAll(s, `"`, "&quot;")
+	}
+
+	return strings.ReplaceAll(s, "'", "&apos;")
+}
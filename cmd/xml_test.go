@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseXPath(t *testing.T) {
+	tests := []struct {
+		expr  string
+		steps []xpathStep
+		attr  string
+		text  bool
+	}{
+		{"//title", []xpathStep{{name: "title", deep: true}}, "", false},
+		{"title", []xpathStep{{name: "title", deep: true}}, "", false},
+		{"/rss/channel/item", []xpathStep{{name: "rss"}, {name: "channel"}, {name: "item"}}, "", false},
+		{"//item[2]/dc:title", []xpathStep{{name: "item", deep: true, index: 2}, {name: "dc:title"}}, "", false},
+		{"//p[@audience='user']", []xpathStep{{name: "p", deep: true, attr: "audience", value: "user", hasValue: true}}, "", false},
+		{`//p[@id][1]`, []xpathStep{{name: "p", deep: true, attr: "id", index: 1}}, "", false},
+		{`//a[@href="x/y"]/b`, []xpathStep{{name: "a", deep: true, attr: "href", value: "x/y", hasValue: true}, {name: "b"}}, "", false},
+		{"//image/@alt", []xpathStep{{name: "image", deep: true}}, "alt", false},
+		{"//@title", []xpathStep{{name: "*", deep: true}}, "title", false},
+		{"//*/@title", []xpathStep{{name: "*", deep: true}}, "title", false},
+		{"//shortdesc/text()", []xpathStep{{name: "shortdesc", deep: true}}, "", true},
+		{"//text()", []xpathStep{{name: "*", deep: true}}, "", true},
+	}
+
+	for _, tt := range tests {
+		sel, err := parseXPath(tt.expr)
+		if err != nil {
+			t.Errorf("parseXPath(%q) error: %v", tt.expr, err)
+			continue
+		}
+		if !slices.Equal(sel.steps, tt.steps) || sel.attr != tt.attr || sel.text != tt.text {
+			t.Errorf("parseXPath(%q) = %+v, want steps %+v, attr %q, text %v", tt.expr, sel, tt.steps, tt.attr, tt.text)
+		}
+	}
+}
+
+func TestParseXPathErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"//a//", "empty step"},
+		{"//@alt/b", "must be the last step"},
+		{"//text()/b", "must be the last step"},
+		{"//p[@id", "missing ]"},
+		{"//p[last()]", "unsupported predicate [last()]"},
+		{"//p[0]", "unsupported predicate [0]"},
+	}
+
+	for _, tt := range tests {
+		_, err := parseXPath(tt.expr)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("parseXPath(%q) error = %v, want %q", tt.expr, err, tt.want)
+		}
+	}
+}
+
+// xpathSelect returns the paths of the elements of doc matched by expr
+func xpathSelect(t *testing.T, expr, doc string) []string {
+	t.Helper()
+	sel, err := parseXPath(expr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	toks, err := scanXML([]byte(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	var chain []xpathNode
+	counts := []map[string]int{{}}
+	anys := []int{0}
+	for _, tok := range toks {
+		switch el := tok.tok.(type) {
+		case xml.StartElement:
+			top := len(counts) - 1
+			counts[top][qualifiedName(el.Name)]++
+			anys[top]++
+			chain = append(chain, xpathNode{el: el, pos: counts[top][qualifiedName(el.Name)], any: anys[top]})
+			counts, anys = append(counts, map[string]int{}), append(anys, 0)
+			if sel.matches(chain) {
+				var path strings.Builder
+				for _, n := range chain {
+					fmt.Fprintf(&path, "/%s[%d]", qualifiedName(n.el.Name), n.pos)
+				}
+				paths = append(paths, path.String())
+			}
+		case xml.EndElement:
+			chain, counts, anys = chain[:len(chain)-1], counts[:len(counts)-1], anys[:len(anys)-1]
+		}
+	}
+
+	return paths
+}
+
+func TestXPathMatches(t *testing.T) {
+	doc := `<rss xmlns:dc="d"><channel><title>Feed</title>` +
+		`<item lang="en"><title>A</title><dc:title>DA</dc:title></item>` +
+		`<note/><item lang="uk"><title>B</title></item></channel></rss>`
+
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{"//title", []string{"/rss[1]/channel[1]/title[1]", "/rss[1]/channel[1]/item[1]/title[1]", "/rss[1]/channel[1]/item[2]/title[1]"}},
+		{"/rss/channel/title", []string{"/rss[1]/channel[1]/title[1]"}},
+		{"/channel/title", nil},
+		{"//item/title", []string{"/rss[1]/channel[1]/item[1]/title[1]", "/rss[1]/channel[1]/item[2]/title[1]"}},
+		{"/rss//item//title", []string{"/rss[1]/channel[1]/item[1]/title[1]", "/rss[1]/channel[1]/item[2]/title[1]"}},
+		{"//item[2]/title", []string{"/rss[1]/channel[1]/item[2]/title[1]"}},
+		{"//channel/*[3]", []string{"/rss[1]/channel[1]/note[1]"}},
+		{"//item[@lang='uk']", []string{"/rss[1]/channel[1]/item[2]"}},
+		{"//item[@lang]", []string{"/rss[1]/channel[1]/item[1]", "/rss[1]/channel[1]/item[2]"}},
+		{"//item[@lang='de']", nil},
+		{"//dc:title", []string{"/rss[1]/channel[1]/item[1]/dc:title[1]"}},
+		{"//item/@lang", []string{"/rss[1]/channel[1]/item[1]", "/rss[1]/channel[1]/item[2]"}},
+		{"//item[1]/text()", []string{"/rss[1]/channel[1]/item[1]"}},
+	}
+
+	for _, tt := range tests {
+		if got := xpathSelect(t, tt.expr, doc); !slices.Equal(got, tt.want) {
+			t.Errorf("%q selects %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestTranslateXMLKeepsEncodingAndEntities(t *testing.T) {
+	// Nothing here has letters to translate, so the document is only
+	// scanned, rewritten and converted back
+	data := "<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?>\n" +
+		"<!DOCTYPE doc [<!ENTITY product \"Gadget\">]>\n" +
+		"<doc note=\"caf\xe9\"><!-- \xe0 bient\xf4t --><p>1 &product; 2 &amp; &#169;</p></doc>\n"
+	sel, err := parseXPath("//p")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := translateXML([]byte(data), []xpathExpr{sel})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != data {
+		t.Errorf("translateXML() = %q, want the input %q", got, data)
+	}
+}
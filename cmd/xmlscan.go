@@ -0,0 +1,269 @@
+/*
+This file provides helpers for rewriting XML documents in place. Instead of
+re-encoding a document with encoding/xml, which drops or renames namespace
+prefixes and normalizes formatting, the document is scanned into raw tokens
+that remember their byte range in the source. Handlers then replace only the
+ranges they translate and everything else is copied byte-for-byte.
+
+Documents declaring another encoding than UTF-8 are converted to UTF-8 before
+being scanned, so that the byte ranges are those of the converted document,
+and converted back afterwards. Entity references the scanner does not know,
+e.g. ones declared in a DTD, become tokens of their own and are kept as they
+are written.
+*/
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+)
+
+// xmlToken is a raw token of an XML document and its byte range in the source
+type xmlToken struct {
+	tok   xml.Token
+	start int
+	end   int
+}
+
+// xmlEntityRef is a reference to an entity unknown to the scanner, e.g. one
+// declared in a DTD; it holds the name of the entity
+type xmlEntityRef string
+
+// xmlEdit replaces the bytes in [start, end) of a document with text
+type xmlEdit struct {
+	start int
+	end   int
+	text  string
+}
+
+// Character marking an unknown entity reference in the scanned text, in the
+// form <mark>name;
+const xmlEntityMark = '\uE000'
+
+var (
+	reXMLDeclEncoding = regexp.MustCompile(`^(?:\xef\xbb\xbf)?<\?xml\s[^>]*?\bencoding\s*=\s*["']([^"']+)["']`)
+	reXMLEntityRef    = regexp.MustCompile(`&([\p{L}_:][\p{L}\p{N}_:.-]*);`)
+	reXMLEntityMark   = regexp.MustCompile(string(xmlEntityMark) + `([^;]*);`)
+)
+
+// **************************************************************************
+// scanXML splits an XML document into raw tokens. Namespace prefixes are not
+// resolved (Name.Space holds the prefix as written) and HTML entities such as
+// &nbsp; are accepted so that XHTML content can be scanned as well. Other
+// entity references split the character data around them and become
+// xmlEntityRef tokens; in attribute values they are marked and restored by
+// escapeXMLText. A document declaring another encoding than UTF-8 must have
+// been converted with decodeXML.
+// --------------------------------------------------------------------------
+func scanXML(data []byte) ([]xmlToken, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	dec.Entity = xmlEntities(data)
+	dec.CharsetReader = func(label string, r io.Reader) (io.Reader, error) {
+		// The content has already been converted by decodeXML
+		if _, err := lookupEncoding(label); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	var toks []xmlToken
+	for {
+		start := int(dec.InputOffset())
+		tok, err := dec.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid XML: %v", err)
+		}
+		t := xmlToken{tok: xml.CopyToken(tok), start: start, end: int(dec.InputOffset())}
+		toks = appendXMLToken(toks, data, t, dec.Entity)
+	}
+
+	return toks, nil
+}
+
+// xmlEntities returns the HTML entities and a marked replacement text for
+// every other entity referenced in data
+func xmlEntities(data []byte) map[string]string {
+	entities := xml.HTMLEntity
+	for _, m := range reXMLEntityRef.FindAllSubmatch(data, -1) {
+		name := string(m[1])
+		if _, ok := entities[name]; ok || xmlPredefinedEntity(name) {
+			continue
+		}
+		if len(entities) == len(xml.HTMLEntity) {
+			entities = maps.Clone(xml.HTMLEntity) // Shared by all decoders
+		}
+		entities[name] = string(xmlEntityMark) + name + ";"
+	}
+
+	return entities
+}
+
+// xmlPredefinedEntity reports whether name is one of the entities of XML
+func xmlPredefinedEntity(name string) bool {
+	switch name {
+	case "amp", "lt", "gt", "apos", "quot":
+		return true
+	}
+
+	return false
+}
+
+// appendXMLToken appends a token, splitting character data at the marked
+// entity references into character data and xmlEntityRef tokens
+func appendXMLToken(toks []xmlToken, data []byte, t xmlToken, entities map[string]string) []xmlToken {
+	text, ok := t.tok.(xml.CharData)
+	if !ok || !bytes.ContainsRune(text, xmlEntityMark) {
+		return append(toks, t)
+	}
+
+	var refs [][]int
+	for _, loc := range reXMLEntityRef.FindAllSubmatchIndex(data[t.start:t.end], -1) {
+		name := string(data[t.start+loc[2] : t.start+loc[3]])
+		if r, _ := utf8.DecodeRuneInString(entities[name]); r == xmlEntityMark {
+			refs = append(refs, loc)
+		}
+	}
+	marks := reXMLEntityMark.FindAllSubmatchIndex(text, -1)
+	if len(refs) != len(marks) {
+		return append(toks, t)
+	}
+
+	pos, src := 0, t.start
+	for k, m := range marks {
+		ref := refs[k]
+		if m[0] > pos {
+			toks = append(toks, xmlToken{tok: xml.CharData(text[pos:m[0]]), start: src, end: t.start + ref[0]})
+		}
+		toks = append(toks, xmlToken{tok: xmlEntityRef(text[m[2]:m[3]]), start: t.start + ref[0], end: t.start + ref[1]})
+		pos, src = m[1], t.start+ref[1]
+	}
+	if pos < len(text) {
+		toks = append(toks, xmlToken{tok: xml.CharData(text[pos:]), start: src, end: t.end})
+	}
+
+	return toks
+}
+
+// **************************************************************************
+// decodeXML converts a document declaring another encoding than UTF-8, e.g.
+// ISO-8859-1 or windows-1252, to UTF-8 and returns the declared encoding, or
+// nil for UTF-8. The declaration itself is left as it is.
+// --------------------------------------------------------------------------
+func decodeXML(data []byte) ([]byte, encoding.Encoding, error) {
+	m := reXMLDeclEncoding.FindSubmatch(data)
+	if m == nil {
+		return data, nil, nil
+	}
+	enc, err := lookupEncoding(string(m[1]))
+	if err != nil || enc == nil {
+		return data, nil, err
+	}
+
+	text, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid %s document: %v", m[1], err)
+	}
+
+	return text, enc, nil
+}
+
+// encodeXML converts a document from UTF-8 back to the encoding returned by
+// decodeXML. Characters the encoding cannot represent are written as
+// character references.
+func encodeXML(data []byte, enc encoding.Encoding) ([]byte, error) {
+	if enc == nil {
+		return data, nil
+	}
+
+	return encoding.HTMLEscapeUnsupported(enc.NewEncoder()).Bytes(data)
+}
+
+// applyXMLEdits returns a copy of data with the edits applied. Edits must not
+// overlap; their order does not matter.
+func applyXMLEdits(data []byte, edits []xmlEdit) []byte {
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+	pos := 0
+	for _, e := range edits {
+		buf.Write(data[pos:e.start])
+		buf.WriteString(e.text)
+		pos = e.end
+	}
+	buf.Write(data[pos:])
+
+	return buf.Bytes()
+}
+
+// xmlAttr returns the value of the attribute with the given local name
+func xmlAttr(el xml.StartElement, local string) (string, bool) {
+	for _, a := range el.Attr {
+		if a.Name.Local == local {
+			return a.Value, true
+		}
+	}
+
+	return "", false
+}
+
+var xmlTextEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// escapeXMLText escapes character data. Unlike xml.EscapeText it leaves
+// newlines and quotes alone, and it restores marked entity references.
+func escapeXMLText(s string) string {
+	return reXMLEntityMark.ReplaceAllString(xmlTextEscaper.Replace(s), "&$1;")
+}
+
+// xmlElementEnd returns the index of the token closing the element opened at
+// toks[open]. Self-closing elements are followed by a synthetic EndElement.
+func xmlElementEnd(toks []xmlToken, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch toks[i].tok.(type) {
+		case xml.StartElement:
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(toks) - 1
+}
+
+// xmlRootElement returns the index of the document element, or -1
+func xmlRootElement(toks []xmlToken) int {
+	for i, t := range toks {
+		if _, ok := t.tok.(xml.StartElement); ok {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// xmlCharData concatenates the character data among toks
+func xmlCharData(toks []xmlToken) string {
+	var sb strings.Builder
+	for _, t := range toks {
+		if data, ok := t.tok.(xml.CharData); ok {
+			sb.Write(data)
+		}
+	}
+
+	return sb.String()
+}
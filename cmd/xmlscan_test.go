@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestApplyXMLEdits(t *testing.T) {
+	data := []byte("<a>one</a><b>two</b>")
+
+	tests := []struct {
+		edits []xmlEdit
+		want  string
+	}{
+		{nil, "<a>one</a><b>two</b>"},
+		{[]xmlEdit{{start: 3, end: 6, text: "один"}}, "<a>один</a><b>two</b>"},
+		{[]xmlEdit{{start: 13, end: 16, text: "два"}, {start: 3, end: 6, text: "один"}}, "<a>один</a><b>два</b>"},
+		{[]xmlEdit{{start: 3, end: 3, text: "+"}}, "<a>+one</a><b>two</b>"},
+		{[]xmlEdit{{start: 0, end: 20, text: "<c/>"}}, "<c/>"},
+	}
+
+	for _, tt := range tests {
+		if got := string(applyXMLEdits(data, tt.edits)); got != tt.want {
+			t.Errorf("applyXMLEdits(%+v) = %q, want %q", tt.edits, got, tt.want)
+		}
+	}
+	if string(data) != "<a>one</a><b>two</b>" {
+		t.Errorf("applyXMLEdits() modified its input: %q", data)
+	}
+}
+
+func TestScanXMLOffsets(t *testing.T) {
+	data := `<?xml version="1.0"?>
+<resources xmlns:tools="x"><string name="a" tools:ignore="y">Hi &amp; bye</string><!-- note --></resources>`
+	toks, err := scanXML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every token covers its own source text, and the tokens cover the
+	// whole document
+	pos := 0
+	for _, tok := range toks {
+		if tok.start != pos {
+			t.Fatalf("token %T starts at %d, want %d", tok.tok, tok.start, pos)
+		}
+		pos = tok.end
+		if el, ok := tok.tok.(xml.StartElement); ok && el.Name.Local == "string" {
+			if want := `<string name="a" tools:ignore="y">`; data[tok.start:tok.end] != want {
+				t.Errorf("start element covers %q, want %q", data[tok.start:tok.end], want)
+			}
+			if el.Attr[1].Name.Space != "tools" {
+				t.Errorf("attribute prefix %q, want tools", el.Attr[1].Name.Space)
+			}
+		}
+	}
+	if pos != len(data) {
+		t.Errorf("tokens end at %d, want %d", pos, len(data))
+	}
+}
+
+func TestScanXMLUnknownEntities(t *testing.T) {
+	data := `<p title="a &brand; b">Buy &product; now &amp; &nbsp;later&product;</p>`
+	toks, err := scanXML([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, tok := range toks[1 : len(toks)-1] {
+		got = append(got, fmt.Sprintf("%T %q", tok.tok, data[tok.start:tok.end]))
+	}
+	want := []string{
+		`xml.CharData "Buy "`,
+		`cmd.xmlEntityRef "&product;"`,
+		`xml.CharData " now &amp; &nbsp;later"`,
+		`cmd.xmlEntityRef "&product;"`,
+	}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("tokens %q, want %q", got, want)
+	}
+	if text := string(toks[3].tok.(xml.CharData)); text != " now &  later" {
+		t.Errorf("character data %q, want the entities resolved", text)
+	}
+
+	// Marked references in attribute values are restored when escaped
+	title, _ := xmlAttr(toks[0].tok.(xml.StartElement), "title")
+	if got := escapeXMLText(strings.Replace(title, "a", "x <", 1)); got != "x &lt; &brand; b" {
+		t.Errorf("escapeXMLText(%q) = %q, want x &lt; &brand; b", title, got)
+	}
+}
+
+func TestDecodeXML(t *testing.T) {
+	data := []byte("<?xml version='1.0' encoding='windows-1252'?><p>caf\xe9 \x80</p>")
+	text, enc, err := decodeXML(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc == nil || string(text) != "<?xml version='1.0' encoding='windows-1252'?><p>café €</p>" {
+		t.Fatalf("decodeXML() = %q, %v", text, enc)
+	}
+
+	// Characters missing from the encoding become character references
+	out, err := encodeXML([]byte(strings.Replace(string(text), "café", "кафе", 1)), enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "<?xml version='1.0' encoding='windows-1252'?><p>&#1082;&#1072;&#1092;&#1077; \x80</p>"; string(out) != want {
+		t.Errorf("encodeXML() = %q, want %q", out, want)
+	}
+
+	for _, doc := range []string{`<p>é</p>`, `<?xml version="1.0"?><p>é</p>`, `<?xml version="1.0" encoding="UTF-8"?><p>é</p>`} {
+		if text, enc, err := decodeXML([]byte(doc)); err != nil || enc != nil || string(text) != doc {
+			t.Errorf("decodeXML(%q) = %q, %v, %v, want it unchanged", doc, text, enc, err)
+		}
+	}
+	if _, _, err := decodeXML([]byte(`<?xml version="1.0" encoding="x-unknown"?><p/>`)); err == nil {
+		t.Errorf("decodeXML() accepted an unknown encoding")
+	}
+}
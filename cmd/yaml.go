@@ -0,0 +1,246 @@
+/*
+Copyright © 2025 Valentyn Solomko <valentyn.solomko@gmail.com>
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Rails interpolations (%{name}, %<n>d), printf verbs and {{mustache}} variables.
+// The space flag of printf is left out, so that "100% free" and "5% discount" in
+// prose are not taken for verbs.
+var reYAMLPlaceholder = regexp.MustCompile(`%\{[^{}]*\}|%<[^<>]*>[-+#0]*\d*(?:\.\d+)?[a-zA-Z]|%[-+#0]*\d*(?:\.\d+)?[sdif]|\{\{[^{}]*\}\}`)
+
+// yamlCmd represents the yaml command
+var yamlCmd = &cobra.Command{
+	Use:   "yaml",
+	Short: "Translate YAML locale files",
+	Long: `Translates the string values of YAML files such as Rails config/locales/en.yml.
+Comments, anchors, aliases and key order are preserved, and interpolations like
+%{count} are never sent for translation.
+
+When the root mapping has a single key equal to the source language (or, with
+--source auto, to the input file name, e.g. "en" for en.yml) that key is renamed
+to the target language.
+
+Key paths are dot-separated and can be limited with --include and --exclude
+patterns, where "*" matches one key and "**" any number of keys. A pattern also
+selects everything below the matched key:
+
+  gootrago yaml -i en.yml -o uk.yml -t uk --include 'en.activerecord' --exclude '**.format'`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runFileHandler(translateYAML)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(yamlCmd)
+
+	yamlCmd.Flags().StringSliceVarP(&yamlInclude, "include", "", []string{}, "Key path patterns to translate (can be specified multiple times)")
+	yamlCmd.Flags().StringSliceVarP(&yamlExclude, "exclude", "", []string{}, "Key path patterns to leave untranslated (can be specified multiple times)")
+}
+
+// **************************************************************************
+// translateYAML is the fileHandler of the yaml command. Every document of a
+// multi-document stream is decoded into a yaml.Node tree, its string scalars
+// selected by the include/exclude patterns are translated in one batch and
+// the tree is encoded back with the indentation of the input.
+// --------------------------------------------------------------------------
+func translateYAML(name string, data []byte) ([]byte, error) {
+	var docs []*yaml.Node
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		doc := &yaml.Node{}
+		if err := dec.Decode(doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("invalid YAML: %v", err)
+		}
+		docs = append(docs, doc)
+	}
+
+	var nodes []*yaml.Node
+	var segs []segment
+	for _, doc := range docs {
+		walkYAMLStrings(doc, nil, func(path []string, node *yaml.Node) {
+			if selectKeyPath(path, yamlInclude, yamlExclude) {
+				nodes = append(nodes, node)
+				segs = append(segs, segment{Text: node.Value})
+			}
+		})
+		renameYAMLRoot(doc, name)
+	}
+
+	strOut, err := translateSegments(segs, regexpProtector(reYAMLPlaceholder))
+	if err != nil {
+		return nil, err
+	}
+	for i, node := range nodes {
+		node.Value = strOut[i]
+	}
+
+	return encodeYAML(docs, detectYAMLIndent(data))
+}
+
+// renameYAMLRoot renames the language key of a Rails-style locale file
+func renameYAMLRoot(doc *yaml.Node, name string) {
+	root := doc
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		root = doc.Content[0]
+	}
+	if root.Kind != yaml.MappingNode || len(root.Content) != 2 {
+		return
+	}
+
+	lang := sourceLang
+	if lang == "auto" {
+		base := filepath.Base(name)
+		lang = strings.TrimSuffix(base, filepath.Ext(base))
+	}
+	if key := root.Content[0]; key.Value == lang {
+		key.Value = targetLang
+	}
+}
+
+// **************************************************************************
+// walkYAMLStrings calls fn for every string scalar below node that is a
+// value (not a key). The path passed to fn holds mapping keys and sequence
+// indices leading to the scalar. Aliases are skipped, so anchored values are
+// visited only once, at their definition.
+// --------------------------------------------------------------------------
+func walkYAMLStrings(node *yaml.Node, path []string, fn func(path []string, node *yaml.Node)) {
+	switch node.Kind {
+	case yaml.DocumentNode:
+		for _, child := range node.Content {
+			walkYAMLStrings(child, path, fn)
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key := node.Content[i]
+			if key.Tag == "!!merge" {
+				continue
+			}
+			walkYAMLStrings(node.Content[i+1], append(path[:len(path):len(path)], key.Value), fn)
+		}
+	case yaml.SequenceNode:
+		for i, child := range node.Content {
+			walkYAMLStrings(child, append(path[:len(path):len(path)], strconv.Itoa(i)), fn)
+		}
+	case yaml.ScalarNode:
+		if node.ShortTag() == "!!str" {
+			fn(path, node)
+		}
+	}
+}
+
+func encodeYAML(docs []*yaml.Node, indent int) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(indent)
+	for _, doc := range docs {
+		clearMergeTags(doc)
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// clearMergeTags resets the tag of "<<" merge keys, which the encoder would
+// otherwise write out as "!!merge <<"
+func clearMergeTags(node *yaml.Node) {
+	if node.Tag == "!!merge" {
+		node.Tag = ""
+	}
+	for _, child := range node.Content {
+		clearMergeTags(child)
+	}
+}
+
+// detectYAMLIndent returns the indentation of the first indented line, or 2
+func detectYAMLIndent(data []byte) int {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " ")
+		if trimmed == "" || trimmed == line || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "- ") {
+			continue
+		}
+		return len(line) - len(trimmed)
+	}
+
+	return 2
+}
+
+// **************************************************************************
+// selectKeyPath reports whether the key path is selected by the include and
+// exclude patterns. An empty include list selects everything. Exclusions win
+// over inclusions.
+// --------------------------------------------------------------------------
+func selectKeyPath(path []string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matchKeyPath(strings.Split(pattern, "."), path) {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if matchKeyPath(strings.Split(pattern, "."), path) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchKeyPath matches a path, or any of its ancestors, against a pattern.
+// Pattern elements are filepath.Match globs; "**" matches any number of keys.
+func matchKeyPath(pattern, path []string) bool {
+	if len(pattern) == 0 {
+		return true
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchKeyPath(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 {
+		return false
+	}
+	if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+		return false
+	}
+
+	return matchKeyPath(pattern[1:], path[1:])
+}
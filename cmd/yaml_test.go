@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestYAMLPlaceholders(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"Hello, %{name}!", []string{"%{name}"}},
+		{"%<count>05.1f items", []string{"%<count>05.1f"}},
+		{"%s of %-3d", []string{"%s", "%-3d"}},
+		{"Hi {{user}}", []string{"{{user}}"}},
+		{"100% free, 5% discount", nil},
+		{"Save 20% during %{month}", []string{"%{month}"}},
+	}
+
+	for _, tt := range tests {
+		if got := reYAMLPlaceholder.FindAllString(tt.in, -1); !slices.Equal(got, tt.want) {
+			t.Errorf("placeholders of %q = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
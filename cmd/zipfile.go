@@ -0,0 +1,136 @@
+/*
+This file provides helpers for container formats built on ZIP archives
+(Office Open XML, OpenDocument, EPUB). Handlers read the entries they want to
+translate and write a new archive in which only those entries are replaced;
+all other entries are copied without recompression, so the order, names and
+compression methods of the original archive are kept.
+*/
+package cmd
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"slices"
+)
+
+// **************************************************************************
+// readZipEntries returns the content of every entry of the archive for which
+// match returns true, keyed by entry name.
+// --------------------------------------------------------------------------
+func readZipEntries(data []byte, match func(name string) bool) (map[string][]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %v", err)
+	}
+
+	entries := make(map[string][]byte)
+	for _, f := range zr.File {
+		if !match(f.Name) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %v: %v", f.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %v: %v", f.Name, err)
+		}
+		entries[f.Name] = content
+	}
+
+	return entries, nil
+}
+
+// **************************************************************************
+// writeZip returns a copy of the archive in which the entries listed in
+// replacements get new content. Replaced entries keep their header (name,
+// modification time, compression method); all others are copied verbatim.
+// A "mimetype" entry, which OpenDocument and EPUB readers expect to find
+// first and uncompressed, is always written first and stored.
+// --------------------------------------------------------------------------
+func writeZip(data []byte, replacements map[string][]byte) ([]byte, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ZIP archive: %v", err)
+	}
+
+	files := slices.Clone(zr.File)
+	for i, f := range files {
+		if f.Name == "mimetype" {
+			copy(files[1:i+1], files[:i])
+			files[0] = f
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		if f.Name == "mimetype" && f.Method != zip.Store {
+			if err := writeStoredEntry(zw, f); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		content, ok := replacements[f.Name]
+		if !ok {
+			if err := zw.Copy(f); err != nil {
+				return nil, fmt.Errorf("failed to copy %v: %v", f.Name, err)
+			}
+			continue
+		}
+
+		header := f.FileHeader
+		header.CRC32, header.CompressedSize64, header.UncompressedSize64 = 0, 0, 0
+		header.CompressedSize, header.UncompressedSize = 0, 0
+		w, err := zw.CreateHeader(&header)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %v: %v", f.Name, err)
+		}
+		if _, err := w.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to write %v: %v", f.Name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write ZIP archive: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// writeStoredEntry writes an entry of the archive uncompressed, without a data
+// descriptor or extra fields
+func writeStoredEntry(zw *zip.Writer, f *zip.File) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %v: %v", f.Name, err)
+	}
+	content, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %v", f.Name, err)
+	}
+
+	header := f.FileHeader
+	header.Method = zip.Store
+	header.Flags &^= 0x8
+	header.Extra = nil
+	header.CRC32 = crc32.ChecksumIEEE(content)
+	header.CompressedSize64 = uint64(len(content))
+	header.UncompressedSize64 = uint64(len(content))
+	w, err := zw.CreateRaw(&header)
+	if err != nil {
+		return fmt.Errorf("failed to write %v: %v", f.Name, err)
+	}
+	if _, err := w.Write(content); err != nil {
+		return fmt.Errorf("failed to write %v: %v", f.Name, err)
+	}
+
+	return nil
+}